@@ -0,0 +1,43 @@
+// wiringgen generates a reflection free resolver for the providers of a
+// package. Providers are constructors annotated with //wiring:provide.
+//
+//	//go:generate go run github.com/4strodev/wiring_graphs/cmd/wiringgen -out wiring_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/4strodev/wiring_graphs/pkg/wiringgen"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package that declares the providers")
+	out := flag.String("out", "wiring_gen.go", "name of the generated file, relative to dir")
+	typeName := flag.String("type", "Wiring", "name of the generated struct")
+	flag.Parse()
+
+	err := run(*dir, *out, *typeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wiringgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, out, typeName string) error {
+	pkg, err := wiringgen.Parse(dir, out)
+	if err != nil {
+		return err
+	}
+
+	source, err := wiringgen.Generate(pkg, wiringgen.Options{
+		TypeName: typeName,
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, out), source, 0o644)
+}
@@ -0,0 +1,428 @@
+// Package config populates configuration structs from files, environment
+// variables and flags, and registers them on a container so they can be
+// injected like any other singleton.
+//
+//	type HTTPConfig struct {
+//		Port    int           `json:"port" env:"HTTP_PORT" flag:"port" default:"8080"`
+//		Timeout time.Duration `json:"timeout" env:"HTTP_TIMEOUT" default:"30s"`
+//		Secret  string        `env:"HTTP_SECRET" required:"true"`
+//	}
+//
+//	err := config.Register[HTTPConfig](cont, config.File("config.json"), config.Env(""), config.Flags(flag.CommandLine))
+//
+// Sources are applied in the following order, so later sources override the
+// values of the previous ones:
+//
+//  1. the default tag
+//  2. files, in the order they were given, matching their keys with the
+//     json, yaml or toml tags depending on the extension of the file. Values
+//     are converted like environment variables, so durations are written
+//     as "30s".
+//  3. the environment variable named by the env tag
+//  4. the flag named by the flag tag when it was set explicitly
+//
+// Fields tagged with required must not be zero once every source is
+// applied. Nested structs are populated with the same rules.
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/convert"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Option adds a source to the configuration
+type Option func(l *loader)
+
+// File decodes the file at path. The format is chosen by its extension:
+// .json, .yaml, .yml or .toml.
+func File(path string) Option {
+	return func(l *loader) {
+		l.files = append(l.files, path)
+	}
+}
+
+// Env reads the environment variables named by the env tags prefixed by
+// prefix
+func Env(prefix string) Option {
+	return func(l *loader) {
+		l.env = true
+		l.envPrefix = prefix
+	}
+}
+
+// Flags reads the flags of fs named by the flag tags. Only flags set
+// explicitly are used, so they don't override other sources with their
+// default value. fs must be parsed before the configuration is loaded.
+func Flags(fs *flag.FlagSet) Option {
+	return func(l *loader) {
+		l.flags = fs
+	}
+}
+
+// loader holds the sources of a configuration
+type loader struct {
+	files     []string
+	env       bool
+	envPrefix string
+	flags     *flag.FlagSet
+	// failures found while loading
+	failures []error
+}
+
+// Load populates the struct pointed by target from the given sources.
+// Invalid values and missing required fields are reported together with
+// code [errors.E_INVALID_CONFIG].
+func Load(target any, options ...Option) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.Errorf(errors.E_TYPE_ERROR, "config expects a struct pointer, %T was given", target)
+	}
+
+	l := &loader{}
+	for _, option := range options {
+		option(l)
+	}
+
+	structValue := value.Elem()
+	name := structValue.Type().Name()
+	l.walk(structValue, name, l.setDefault)
+
+	for _, file := range l.files {
+		values, tag, err := decodeFile(file)
+		if err != nil {
+			return err
+		}
+		l.setFile(structValue, name, tag, "config file "+file, values)
+	}
+
+	if l.env {
+		l.walk(structValue, name, l.setEnv)
+	}
+
+	if l.flags != nil {
+		flags := map[string]string{}
+		l.flags.Visit(func(f *flag.Flag) {
+			flags[f.Name] = f.Value.String()
+		})
+		l.walk(structValue, name, func(field reflect.Value, structField reflect.StructField, path string) {
+			l.setFlag(field, structField, path, flags)
+		})
+	}
+
+	l.walk(structValue, name, l.checkRequired)
+
+	return errors.Join(l.failures...)
+}
+
+// New returns a T populated with [Load]. T must be a struct or a pointer
+// to a struct.
+func New[T any](options ...Option) (T, error) {
+	var result T
+	t := reflect.TypeFor[T]()
+	structType := t
+	if t.Kind() == reflect.Pointer {
+		structType = t.Elem()
+	}
+
+	if structType.Kind() != reflect.Struct {
+		return result, errors.Errorf(errors.E_TYPE_ERROR, "config expects a struct or a struct pointer, %v was given", t)
+	}
+
+	target := reflect.New(structType)
+	err := Load(target.Interface(), options...)
+	if err != nil {
+		return result, err
+	}
+
+	if t.Kind() != reflect.Pointer {
+		target = target.Elem()
+	}
+	reflect.ValueOf(&result).Elem().Set(target.Convert(t))
+
+	return result, nil
+}
+
+// Register registers a singleton resolver for T built with [New]. The
+// configuration is loaded the first time it's resolved, use
+// [container.Container.Start] to load it on startup.
+func Register[T any](c *container.Container, options ...Option) error {
+	return c.Singleton(func() (T, error) {
+		return New[T](options...)
+	})
+}
+
+// walk calls visit for each exported field of structValue, nested structs
+// are walked instead of visited
+func (l *loader) walk(structValue reflect.Value, path string, visit func(field reflect.Value, structField reflect.StructField, path string)) {
+	structType := structValue.Type()
+	for i := range structType.NumField() {
+		structField := structType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		field := structValue.Field(i)
+		fieldPath := path + "." + structField.Name
+		if isNested(structField) {
+			l.walk(field, fieldPath, visit)
+			continue
+		}
+
+		visit(field, structField, fieldPath)
+	}
+}
+
+// isNested reports if the field is a struct populated field by field
+func isNested(structField reflect.StructField) bool {
+	if structField.Type.Kind() != reflect.Struct {
+		return false
+	}
+
+	if reflect.PointerTo(structField.Type).Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) {
+		return false
+	}
+
+	_, hasEnv := structField.Tag.Lookup("env")
+	_, hasFlag := structField.Tag.Lookup("flag")
+	_, hasDefault := structField.Tag.Lookup("default")
+	return !hasEnv && !hasFlag && !hasDefault
+}
+
+func (l *loader) setDefault(field reflect.Value, structField reflect.StructField, path string) {
+	value, ok := structField.Tag.Lookup("default")
+	if !ok {
+		return
+	}
+
+	l.set(field, path, "default value", value)
+}
+
+func (l *loader) setEnv(field reflect.Value, structField reflect.StructField, path string) {
+	name := structField.Tag.Get("env")
+	if name == "" {
+		return
+	}
+
+	name = l.envPrefix + name
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return
+	}
+
+	l.set(field, path, "environment variable "+name, value)
+}
+
+func (l *loader) setFlag(field reflect.Value, structField reflect.StructField, path string, flags map[string]string) {
+	name := structField.Tag.Get("flag")
+	if name == "" {
+		return
+	}
+
+	value, ok := flags[name]
+	if !ok {
+		return
+	}
+
+	l.set(field, path, "flag -"+name, value)
+}
+
+func (l *loader) checkRequired(field reflect.Value, structField reflect.StructField, path string) {
+	if structField.Tag.Get("required") != "true" || !field.IsZero() {
+		return
+	}
+
+	sources := []string{}
+	if name := structField.Tag.Get("env"); name != "" {
+		sources = append(sources, "environment variable "+l.envPrefix+name)
+	}
+	if name := structField.Tag.Get("flag"); name != "" {
+		sources = append(sources, "flag -"+name)
+	}
+
+	message := "missing required config field " + path
+	if len(sources) > 0 {
+		message += ", set it with " + strings.Join(sources, " or ")
+	}
+	l.failures = append(l.failures, errors.Errorf(errors.E_INVALID_CONFIG, "%s", message))
+}
+
+// set converts value and assigns it to field
+func (l *loader) set(field reflect.Value, path, source, value string) {
+	converted, err := convert.FromString(value, field.Type())
+	if err != nil {
+		l.failures = append(l.failures, errors.Errorf(errors.E_INVALID_CONFIG, "config field %s from %s: %w", path, source, err))
+		return
+	}
+
+	field.Set(converted)
+}
+
+// decodeFile decodes the file at path into a map and the name of the tag
+// used to match its keys
+func decodeFile(path string) (map[string]any, string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", errors.Errorf(errors.E_INVALID_CONFIG, "cannot read config file: %w", err)
+	}
+
+	values := map[string]any{}
+	var tag string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		tag = "json"
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		// numbers are kept as text so they are converted like any other
+		// value
+		decoder.UseNumber()
+		err = decoder.Decode(&values)
+	case ".yaml", ".yml":
+		tag = "yaml"
+		err = yaml.Unmarshal(content, &values)
+	case ".toml":
+		tag = "toml"
+		err = toml.Unmarshal(content, &values)
+	default:
+		return nil, "", errors.Errorf(errors.E_INVALID_CONFIG, "unsupported config file format %s", path)
+	}
+	if err != nil {
+		return nil, "", errors.Errorf(errors.E_INVALID_CONFIG, "cannot decode config file %s: %w", path, err)
+	}
+
+	return values, tag, nil
+}
+
+// setFile assigns the values decoded from a file to the fields of
+// structValue. Keys match the name given by tag or the name of the field,
+// ignoring case.
+func (l *loader) setFile(structValue reflect.Value, path, tag, source string, values map[string]any) {
+	structType := structValue.Type()
+	for i := range structType.NumField() {
+		structField := structType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(structField.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+
+		value, ok := lookupKey(values, name)
+		if !ok {
+			continue
+		}
+
+		l.setFileValue(structValue.Field(i), path+"."+structField.Name, tag, source, value)
+	}
+}
+
+// setFileValue assigns a decoded value to field. Scalars are converted from
+// their text like environment variables, so durations and
+// [encoding.TextUnmarshaler] implementations can be written as strings.
+func (l *loader) setFileValue(field reflect.Value, path, tag, source string, value any) {
+	if value == nil {
+		return
+	}
+
+	t := field.Type()
+	decoded := reflect.ValueOf(value)
+	switch {
+	case decoded.Kind() == reflect.Slice && t.Kind() == reflect.Slice:
+		items := reflect.MakeSlice(t, decoded.Len(), decoded.Len())
+		for i := range decoded.Len() {
+			l.setFileValue(items.Index(i), fmt.Sprintf("%s[%d]", path, i), tag, source, decoded.Index(i).Interface())
+		}
+		field.Set(items)
+	case decoded.Kind() == reflect.Map && isStruct(t):
+		if t.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				field.Set(reflect.New(t.Elem()))
+			}
+			field = field.Elem()
+		}
+		l.setFile(field, path, tag, source, stringKeys(decoded))
+	case decoded.Kind() == reflect.Map && t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		if field.IsNil() {
+			field.Set(reflect.MakeMap(t))
+		}
+		for key, item := range stringKeys(decoded) {
+			elem := reflect.New(t.Elem()).Elem()
+			l.setFileValue(elem, path+"."+key, tag, source, item)
+			field.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+	case decoded.Kind() != reflect.Slice && decoded.Kind() != reflect.Map && convert.Supported(t):
+		l.set(field, path, source, text(value))
+	default:
+		l.failures = append(l.failures, errors.Errorf(errors.E_INVALID_CONFIG, "config field %s from %s: cannot assign %T to %v", path, source, value, t))
+	}
+}
+
+// isStruct reports if t is a struct, or a pointer to one, populated field
+// by field
+func isStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && !convert.Supported(t)
+}
+
+// lookupKey returns the value of key, matching it ignoring case when there
+// is no exact match
+func lookupKey(values map[string]any, key string) (any, bool) {
+	value, ok := values[key]
+	if ok {
+		return value, true
+	}
+
+	keys := slices.Sorted(maps.Keys(values))
+	for _, candidate := range keys {
+		if strings.EqualFold(candidate, key) {
+			return values[candidate], true
+		}
+	}
+
+	return nil, false
+}
+
+// stringKeys returns the entries of a decoded map by their textual key
+func stringKeys(decoded reflect.Value) map[string]any {
+	values := map[string]any{}
+	iter := decoded.MapRange()
+	for iter.Next() {
+		values[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+	}
+
+	return values
+}
+
+// text returns the textual form of a decoded scalar
+func text(value any) string {
+	marshaler, ok := value.(encoding.TextMarshaler)
+	if ok {
+		content, err := marshaler.MarshalText()
+		if err == nil {
+			return string(content)
+		}
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,181 @@
+package config_test
+
+import (
+	"flag"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/4strodev/wiring_graphs/pkg/config"
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type DatabaseConfig struct {
+	URL      string `json:"url" yaml:"url" toml:"url" env:"DATABASE_URL" required:"true"`
+	MaxConns int    `json:"maxConns" yaml:"maxConns" toml:"maxConns" default:"10"`
+}
+
+type AppConfig struct {
+	Name     string        `json:"name" yaml:"name" toml:"name" default:"app"`
+	Port     int           `json:"port" yaml:"port" toml:"port" env:"PORT" flag:"port" default:"8080"`
+	Timeout  time.Duration `env:"TIMEOUT" default:"30s"`
+	Debug    bool          `env:"DEBUG" flag:"debug"`
+	Origins  []string      `json:"origins" yaml:"origins" toml:"origins" env:"ORIGINS"`
+	Database DatabaseConfig
+	ignored  string
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0o600)
+	require.NoError(t, err)
+
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	t.Setenv("APP_DATABASE_URL", "postgres://localhost")
+
+	var cfg AppConfig
+	err := config.Load(&cfg, config.Env("APP_"))
+	require.NoError(t, err)
+	require.Equal(t, AppConfig{
+		Name:    "app",
+		Port:    8080,
+		Timeout: 30 * time.Second,
+		Database: DatabaseConfig{
+			URL:      "postgres://localhost",
+			MaxConns: 10,
+		},
+	}, cfg)
+}
+
+func TestLoad_Files(t *testing.T) {
+	files := map[string]string{
+		"config.json": `{"name": "api", "port": 9000, "origins": ["a", "b"], "Database": {"url": "db", "maxConns": 5}}`,
+		"config.yaml": "name: api\nport: 9000\norigins: [a, b]\ndatabase:\n  url: db\n  maxConns: 5\n",
+		"config.toml": "name = 'api'\nport = 9000\norigins = ['a', 'b']\n[Database]\nurl = 'db'\nmaxConns = 5\n",
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			var cfg AppConfig
+			err := config.Load(&cfg, config.File(writeFile(t, name, content)))
+			require.NoError(t, err)
+			require.Equal(t, "api", cfg.Name)
+			require.Equal(t, 9000, cfg.Port)
+			require.Equal(t, []string{"a", "b"}, cfg.Origins)
+			require.Equal(t, DatabaseConfig{URL: "db", MaxConns: 5}, cfg.Database)
+			require.Equal(t, 30*time.Second, cfg.Timeout, "defaults should be kept for missing keys")
+		})
+	}
+}
+
+type ServerConfig struct {
+	Timeout time.Duration `json:"timeout" yaml:"timeout" toml:"timeout"`
+	Address netip.Addr    `json:"address" yaml:"address" toml:"address"`
+	Retries []time.Duration
+}
+
+func TestLoad_FileConversions(t *testing.T) {
+	files := map[string]string{
+		"config.json": `{"timeout": "5s", "address": "127.0.0.1", "retries": ["1s", "2s"]}`,
+		"config.yaml": "timeout: 5s\naddress: 127.0.0.1\nretries: [1s, 2s]\n",
+		"config.toml": "timeout = '5s'\naddress = '127.0.0.1'\nretries = ['1s', '2s']\n",
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			var cfg ServerConfig
+			err := config.Load(&cfg, config.File(writeFile(t, name, content)))
+			require.NoError(t, err)
+			require.Equal(t, ServerConfig{
+				Timeout: 5 * time.Second,
+				Address: netip.MustParseAddr("127.0.0.1"),
+				Retries: []time.Duration{time.Second, 2 * time.Second},
+			}, cfg)
+		})
+	}
+
+	var cfg ServerConfig
+	err := config.Load(&cfg, config.File(writeFile(t, "config.json", `{"timeout": "soon"}`)))
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_INVALID_CONFIG, wiringErr.Code())
+	require.ErrorContains(t, err, "ServerConfig.Timeout from config file")
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	file := writeFile(t, "config.json", `{"port": 9000, "Database": {"url": "db"}}`)
+	t.Setenv("PORT", "9001")
+	t.Setenv("ORIGINS", "a, b")
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.Int("port", 0, "")
+	fs.Bool("debug", false, "")
+	err := fs.Parse([]string{"-debug"})
+	require.NoError(t, err)
+
+	var cfg AppConfig
+	err = config.Load(&cfg, config.File(file), config.Env(""), config.Flags(fs))
+	require.NoError(t, err)
+	require.Equal(t, 9001, cfg.Port, "unset flags should not override other sources")
+	require.True(t, cfg.Debug)
+	require.Equal(t, []string{"a", "b"}, cfg.Origins)
+
+	err = fs.Parse([]string{"-port", "9002"})
+	require.NoError(t, err)
+	err = config.Load(&cfg, config.File(file), config.Env(""), config.Flags(fs))
+	require.NoError(t, err)
+	require.Equal(t, 9002, cfg.Port)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Setenv("PORT", "eighty")
+	t.Setenv("TIMEOUT", "soon")
+
+	var cfg AppConfig
+	err := config.Load(&cfg, config.Env(""))
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_MULTIPLE_ERRORS, wiringErr.Code())
+	require.ErrorContains(t, err, "AppConfig.Port from environment variable PORT")
+	require.ErrorContains(t, err, "AppConfig.Timeout from environment variable TIMEOUT")
+	require.ErrorContains(t, err, "missing required config field AppConfig.Database.URL, set it with environment variable DATABASE_URL")
+
+	err = config.Load(&cfg, config.File(writeFile(t, "config.ini", "")))
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_INVALID_CONFIG, wiringErr.Code())
+
+	err = config.Load(cfg)
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_TYPE_ERROR, wiringErr.Code())
+}
+
+func TestRegister(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost")
+
+	cont := container.New()
+	err := config.Register[*AppConfig](cont, config.Env(""))
+	require.NoError(t, err)
+	err = config.Register[DatabaseConfig](cont, config.Env(""))
+	require.NoError(t, err)
+
+	cfg, err := container.Resolve[*AppConfig](cont)
+	require.NoError(t, err)
+	require.Equal(t, "postgres://localhost", cfg.Database.URL)
+
+	again, err := container.Resolve[*AppConfig](cont)
+	require.NoError(t, err)
+	require.Same(t, cfg, again, "configs should be singletons")
+
+	database, err := container.Resolve[DatabaseConfig](cont)
+	require.NoError(t, err)
+	require.Equal(t, 10, database.MaxConns)
+}
@@ -0,0 +1,210 @@
+package container
+
+import (
+	"reflect"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/resolver"
+)
+
+// This file contains the logic of assisted injection. A constructor can mix
+// parameters known by the container with parameters that are only known at
+// runtime. The container generates a factory function that receives the
+// runtime parameters and injects the rest.
+
+// NewFactory returns a function of type F that calls constructor. The
+// parameters of constructor that can be resolved by the container are
+// injected, the remaining ones must match, in order, the parameters of F.
+//
+//	func NewJob(logger *slog.Logger, repo *Repository, jobID string) *Job
+//
+//	newJob, err := container.NewFactory[func(jobID string) *Job](cont, NewJob)
+//	job := newJob("42")
+//
+// F must return the type built by constructor and optionally an error. When
+// F does not return an error, errors resolving the injected parameters panic
+// and constructor must not return an error.
+func NewFactory[F any](c *Container, constructor any) (F, error) {
+	var factory F
+	if !resolver.IsValid(constructor) {
+		return factory, errors.Errorf(errors.E_INVALID_RESOLVER, "Invalid resolver")
+	}
+
+	err := c.ensureNodesConnected()
+	if err != nil {
+		return factory, err
+	}
+
+	constructorType := reflect.TypeOf(constructor)
+	injected := make([]bool, constructorType.NumIn())
+	for i := range injected {
+		injected[i] = c.canResolve(constructorType.In(i))
+	}
+
+	return newFactory[F](c, constructor, injected)
+}
+
+// newFactory builds the factory of [NewFactory]. injected tells which
+// parameters of constructor are resolved by the container.
+func newFactory[F any](c *Container, constructor any, injected []bool) (F, error) {
+	var factory F
+	factoryType := reflect.TypeFor[F]()
+	if factoryType.Kind() != reflect.Func {
+		return factory, errors.Errorf(errors.E_TYPE_ERROR, "factory type should be a function, %v was given", factoryType)
+	}
+
+	dependencyResolver := resolver.DependencyResolver[any]{
+		Resolver: constructor,
+	}
+
+	inputTypes := dependencyResolver.Input()
+	runtimeTypes := []reflect.Type{}
+	for i, inputType := range inputTypes {
+		if !injected[i] {
+			runtimeTypes = append(runtimeTypes, inputType)
+		}
+	}
+
+	if len(runtimeTypes) != factoryType.NumIn() {
+		return factory, errors.Errorf(
+			errors.E_TYPE_ERROR,
+			"factory %v should receive %d parameters not provided by the container %v",
+			factoryType,
+			len(runtimeTypes),
+			runtimeTypes)
+	}
+
+	for i, runtimeType := range runtimeTypes {
+		if !factoryType.In(i).AssignableTo(runtimeType) {
+			return factory, errors.Errorf(
+				errors.E_TYPE_ERROR,
+				"parameter %d of factory %v is not assignable to %v",
+				i,
+				factoryType,
+				runtimeType)
+		}
+	}
+
+	returnsError := factoryType.NumOut() == 2 && factoryType.Out(1) == reflect.TypeFor[error]()
+	if factoryType.NumOut() != 1 && !returnsError {
+		return factory, errors.Errorf(errors.E_TYPE_ERROR, "factory %v should return a value and optionally an error", factoryType)
+	}
+
+	outputType := factoryType.Out(0)
+	if !dependencyResolver.Type().AssignableTo(outputType) {
+		return factory, errors.Errorf(
+			errors.E_TYPE_ERROR,
+			"constructor returns %v which is not assignable to %v",
+			dependencyResolver.Type(),
+			outputType)
+	}
+
+	if reflect.TypeOf(constructor).NumOut() == 2 && !returnsError {
+		return factory, errors.Errorf(errors.E_TYPE_ERROR, "factory %v should return an error like its constructor", factoryType)
+	}
+
+	results := func(value reflect.Value, err error) []reflect.Value {
+		if err != nil && !returnsError {
+			panic(err)
+		}
+
+		output := reflect.New(outputType).Elem()
+		if err == nil {
+			output.Set(value)
+		}
+
+		if !returnsError {
+			return []reflect.Value{output}
+		}
+		return []reflect.Value{output, reflect.ValueOf(&err).Elem()}
+	}
+
+	factoryValue := reflect.MakeFunc(factoryType, func(args []reflect.Value) []reflect.Value {
+		inputArgs := make([]reflect.Value, len(inputTypes))
+		nextArg := 0
+		for i, inputType := range inputTypes {
+			if !injected[i] {
+				inputArgs[i] = args[nextArg]
+				nextArg++
+				continue
+			}
+
+			arg, err := c.resolve(inputType, nil)
+			if err != nil {
+				return results(reflect.Value{}, err)
+			}
+			inputArgs[i] = arg
+		}
+
+		return results(resolver.Execute(dependencyResolver, inputArgs))
+	})
+
+	reflect.ValueOf(&factory).Elem().Set(factoryValue)
+	return factory, nil
+}
+
+// Assisted registers a singleton resolver for the factory type F built
+// with [NewFactory]. The factory can then be resolved or injected like any
+// other dependency.
+//
+// The parameters of F are matched, starting from the last one, with the
+// parameters of constructor. The remaining parameters of constructor are
+// injected and they are dependencies of the factory on the graph, so
+// missing and circular dependencies are detected before calling it.
+func Assisted[F any](c *Container, constructor any) error {
+	factoryType := reflect.TypeFor[F]()
+	if factoryType.Kind() != reflect.Func {
+		return errors.Errorf(errors.E_TYPE_ERROR, "factory type should be a function, %v was given", factoryType)
+	}
+
+	if !resolver.IsValid(constructor) {
+		return errors.Errorf(errors.E_INVALID_RESOLVER, "Invalid resolver")
+	}
+
+	constructorType := reflect.TypeOf(constructor)
+	injected, err := injectedParameters(constructorType, factoryType)
+	if err != nil {
+		return err
+	}
+
+	inputTypes := []reflect.Type{}
+	for i, isInjected := range injected {
+		if isInjected {
+			inputTypes = append(inputTypes, constructorType.In(i))
+		}
+	}
+	inputTypes = append(inputTypes, reflect.TypeFor[*Container]())
+	outputTypes := []reflect.Type{factoryType, reflect.TypeFor[error]()}
+
+	// injected parameters are inputs so they become edges of the graph,
+	// the factory resolves them again on each call
+	res := reflect.MakeFunc(reflect.FuncOf(inputTypes, outputTypes, false), func(args []reflect.Value) []reflect.Value {
+		scope := args[len(args)-1].Interface().(*Container)
+		factory, err := newFactory[F](scope, constructor, injected)
+		return []reflect.Value{reflect.ValueOf(&factory).Elem(), reflect.ValueOf(&err).Elem()}
+	})
+
+	return c.Singleton(res.Interface())
+}
+
+// injectedParameters matches the parameters of factoryType with the last
+// assignable parameters of constructorType and reports the rest as
+// injected
+func injectedParameters(constructorType, factoryType reflect.Type) ([]bool, error) {
+	injected := make([]bool, constructorType.NumIn())
+	next := factoryType.NumIn() - 1
+	for i := constructorType.NumIn() - 1; i >= 0; i-- {
+		if next >= 0 && factoryType.In(next).AssignableTo(constructorType.In(i)) {
+			next--
+			continue
+		}
+
+		injected[i] = true
+	}
+
+	if next >= 0 {
+		return nil, errors.Errorf(errors.E_TYPE_ERROR, "parameters of factory %v don't match the parameters of %v", factoryType, constructorType)
+	}
+
+	return injected, nil
+}
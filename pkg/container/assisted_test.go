@@ -0,0 +1,125 @@
+package container_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+type job struct {
+	service testutils.MyService
+	buffer  *bytes.Buffer
+	id      string
+}
+
+func newJob(service testutils.MyService, id string, buffer *bytes.Buffer) *job {
+	return &job{
+		service: service,
+		buffer:  buffer,
+		id:      id,
+	}
+}
+
+func TestNewFactory(t *testing.T) {
+	cont := container.New()
+	cont.Transient(testutils.NewService, func() *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+
+	newJobFactory, err := container.NewFactory[func(id string) *job](cont, newJob)
+	require.NoError(t, err)
+
+	job := newJobFactory("42")
+	require.Equal(t, "42", job.id)
+	require.NotNil(t, job.buffer)
+}
+
+func TestNewFactory_ParameterMismatch(t *testing.T) {
+	cont := container.New()
+	cont.Transient(testutils.NewService)
+
+	_, err := container.NewFactory[func(id string) *job](cont, newJob)
+	require.Error(t, err, "*bytes.Buffer is not registered so it should be a factory parameter")
+
+	_, err = container.NewFactory[func(id int, buffer *bytes.Buffer) *job](cont, newJob)
+	require.Error(t, err, "parameter types should match the constructor")
+}
+
+func TestNewFactory_ReturnsError(t *testing.T) {
+	cont := container.New()
+
+	constructor := func(service testutils.MyService, id string) (*job, error) {
+		if id == "" {
+			return nil, errors.New("empty id")
+		}
+		return &job{service: service, id: id}, nil
+	}
+
+	_, err := container.NewFactory[func(id string) *job](cont, constructor)
+	require.Error(t, err, "factory should return an error like its constructor")
+
+	cont.Transient(testutils.NewService)
+	newJobFactory, err := container.NewFactory[func(id string) (*job, error)](cont, constructor)
+	require.NoError(t, err)
+
+	_, err = newJobFactory("")
+	require.Error(t, err)
+	job, err := newJobFactory("42")
+	require.NoError(t, err)
+	require.Equal(t, "42", job.id)
+}
+
+func TestAssisted(t *testing.T) {
+	cont := container.New()
+	cont.Transient(testutils.NewService, func() *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+
+	err := container.Assisted[func(string) *job](cont, newJob)
+	require.NoError(t, err)
+
+	type worker struct {
+		newJob func(string) *job
+	}
+	cont.Transient(func(newJob func(string) *job) worker {
+		return worker{newJob: newJob}
+	})
+
+	w, err := container.Resolve[worker](cont)
+	require.NoError(t, err)
+	require.Equal(t, "1", w.newJob("1").id)
+}
+
+func TestAssisted_Graph(t *testing.T) {
+	cont := container.New()
+	cont.Transient(testutils.NewService)
+
+	err := container.Assisted[func(string) *job](cont, newJob)
+	require.NoError(t, err)
+
+	_, err = cont.DetectCircularDependencies()
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code(), "*bytes.Buffer should be required by the factory")
+
+	cont.Transient(func(newJob func(string) *job) *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+	_, err = container.Resolve[func(string) *job](cont)
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_CIRCULAR_DEPENDENCY, wiringErr.Code())
+}
+
+func TestAssisted_ParameterMismatch(t *testing.T) {
+	cont := container.New()
+
+	err := container.Assisted[func(int) *job](cont, newJob)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_TYPE_ERROR, wiringErr.Code())
+}
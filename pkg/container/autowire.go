@@ -0,0 +1,207 @@
+package container
+
+import (
+	"reflect"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/set"
+)
+
+// This file contains the logic to synthesize resolvers for plain structs.
+// The generated resolver receives the type of each field as a parameter so
+// fields become edges on the graph and are validated like any other
+// resolver input. Fields and setters resolved through the container are
+// kept as implicit dependencies of the resolver so they are connected too.
+
+// autowired is the resolver returned by [Struct]
+type autowired struct {
+	resolver any
+	// dependencies resolved through the container by resolver
+	dependencies []implicitDependency
+	// err is returned when the resolver is registered if the struct
+	// cannot be autowired
+	err error
+}
+
+// implicitDependency is a dependency that is not a parameter of the
+// resolver function
+type implicitDependency struct {
+	t reflect.Type
+	// token the dependency is resolved by, empty to resolve it by type
+	token string
+	// optional dependencies are only resolved when there is a resolver
+	// for them, like the parameters of setters
+	optional bool
+}
+
+// Struct returns a resolver that builds T filling its fields with the same
+// rules used by [Container.Fill]. T must be a struct or a pointer to a struct,
+// on any other case registering the resolver fails with the reason.
+//
+//	cont.Singleton(container.Struct[*UserService]())
+func Struct[T any]() any {
+	res, err := structResolver(reflect.TypeFor[T]())
+	if err != nil {
+		return &autowired{err: err}
+	}
+
+	return res
+}
+
+// Autowire registers a transient resolver for the type of each value using
+// [Struct]. Values are only used to get their type.
+//
+//	cont.Autowire(&UserService{}, &UserHandler{})
+func (c *Container) Autowire(values ...any) error {
+	for _, value := range values {
+		res, err := structResolver(reflect.TypeOf(value))
+		if err != nil {
+			return err
+		}
+
+		err = c.Transient(res)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// structResolver builds a resolver function for t. Fields resolved by type
+// are parameters of the function. When some field is resolved by token,
+// filled as a nested struct or the struct has setters the container is
+// injected as the last parameter to resolve them.
+func structResolver(t reflect.Type) (any, error) {
+	if t == nil {
+		return nil, errors.Errorf(errors.E_TYPE_ERROR, "autowire expects a struct or a struct pointer, nil was given")
+	}
+
+	structType := t
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+
+	if structType.Kind() != reflect.Struct {
+		return nil, errors.Errorf(errors.E_TYPE_ERROR, "autowire expects a struct or a struct pointer '%v' was given", t)
+	}
+
+	fields := injectableFields(structType)
+	typedFields := []fieldInjection{}
+	containerFields := []fieldInjection{}
+	inputTypes := []reflect.Type{}
+	for _, field := range fields {
+		if field.token != "" || field.fill {
+			containerFields = append(containerFields, field)
+			continue
+		}
+
+		typedFields = append(typedFields, field)
+		inputTypes = append(inputTypes, field.field.Type)
+	}
+
+	needsContainer := len(containerFields) > 0 || len(setterMethods(reflect.PointerTo(structType))) > 0
+	if needsContainer {
+		inputTypes = append(inputTypes, reflect.TypeFor[*Container]())
+	}
+
+	outputTypes := []reflect.Type{t, reflect.TypeFor[error]()}
+	funcType := reflect.FuncOf(inputTypes, outputTypes, false)
+
+	res := reflect.MakeFunc(funcType, func(args []reflect.Value) []reflect.Value {
+		structValue := reflect.New(structType)
+
+		for i, field := range typedFields {
+			settable(structValue.Elem().Field(field.index), field).Set(args[i])
+		}
+
+		var c *Container
+		if needsContainer {
+			c = args[len(args)-1].Interface().(*Container)
+		}
+
+		f := newFiller(c, FillOptions{})
+		f.visiting.Add(structType)
+		var err error
+		for _, field := range containerFields {
+			err = f.fillField(structValue.Elem().Field(field.index), structType.Name(), field)
+			if err != nil {
+				break
+			}
+		}
+
+		if err == nil {
+			err = f.afterFill(structValue.Elem(), structType.Name())
+		}
+
+		result := structValue
+		if t.Kind() != reflect.Pointer {
+			result = structValue.Elem()
+		}
+		if err != nil {
+			result = reflect.Zero(t)
+		}
+
+		return []reflect.Value{result, reflect.ValueOf(&err).Elem()}
+	})
+
+	visiting := set.New[reflect.Type]()
+	visiting.Add(structType)
+	dependencies := fieldDependencies(containerFields, visiting)
+	dependencies = append(dependencies, setterDependencies(structType)...)
+
+	return &autowired{
+		resolver:     res.Interface(),
+		dependencies: dependencies,
+	}, nil
+}
+
+// fieldDependencies returns the dependencies resolved to fill fields.
+// Fields filled as nested structs add the dependencies of their own fields.
+// visiting holds the structs being filled, self references are reported
+// when the struct is filled.
+func fieldDependencies(fields []fieldInjection, visiting set.Set[reflect.Type]) []implicitDependency {
+	dependencies := []implicitDependency{}
+	for _, field := range fields {
+		if !field.fill {
+			dependencies = append(dependencies, implicitDependency{t: field.field.Type, token: field.token})
+			continue
+		}
+
+		nestedType := field.field.Type
+		if nestedType.Kind() == reflect.Pointer {
+			nestedType = nestedType.Elem()
+		}
+		if nestedType.Kind() != reflect.Struct || visiting.Has(nestedType) {
+			continue
+		}
+
+		visiting.Add(nestedType)
+		dependencies = append(dependencies, fieldDependencies(injectableFields(nestedType), visiting)...)
+		// setters of embedded structs are part of the struct that
+		// embeds them
+		if !field.field.Anonymous {
+			dependencies = append(dependencies, setterDependencies(nestedType)...)
+		}
+		visiting.Remove(nestedType)
+	}
+
+	return dependencies
+}
+
+// setterDependencies returns the parameters of the setters of structType.
+// Deferred parameters are skipped since they never create an edge.
+func setterDependencies(structType reflect.Type) []implicitDependency {
+	dependencies := []implicitDependency{}
+	for _, setter := range setterMethods(reflect.PointerTo(structType)) {
+		paramType := setter.Type.In(1)
+		_, isDeferred := asDeferred(paramType)
+		if isDeferred {
+			continue
+		}
+
+		dependencies = append(dependencies, implicitDependency{t: paramType, optional: true})
+	}
+
+	return dependencies
+}
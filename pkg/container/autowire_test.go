@@ -0,0 +1,150 @@
+package container_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+type autowiredService struct {
+	Service testutils.MyService
+	Buffer  *bytes.Buffer `wiring:"buffer"`
+	Ignored *bytes.Buffer `wiring:",omit"`
+}
+
+func TestStruct(t *testing.T) {
+	cont := container.New()
+
+	cont.Transient(testutils.NewService)
+	cont.Token(map[string]any{
+		"buffer": func() *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		}},
+	)
+	err := cont.Singleton(container.Struct[*autowiredService]())
+	require.NoError(t, err)
+
+	service1, err := container.Resolve[*autowiredService](cont)
+	require.NoError(t, err)
+	require.NotNil(t, service1.Buffer)
+	require.Nil(t, service1.Ignored)
+
+	service2, err := container.Resolve[*autowiredService](cont)
+	require.NoError(t, err)
+	require.Same(t, service1, service2)
+}
+
+func TestStruct_InvalidType(t *testing.T) {
+	cont := container.New()
+
+	err := cont.Transient(container.Struct[int]())
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_TYPE_ERROR, wiringErr.Code())
+	require.ErrorContains(t, err, "struct")
+}
+
+func TestAutowire(t *testing.T) {
+	cont := container.New()
+
+	cont.Transient(testutils.NewService)
+	err := cont.Autowire(testutils.MyDeps{})
+	require.NoError(t, err)
+
+	cont.Token(map[string]any{
+		"buffer": func() *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		}},
+	)
+	deps, err := container.Resolve[testutils.MyDeps](cont)
+	require.NoError(t, err)
+	require.NoError(t, deps.CheckResolvedDependencies())
+}
+
+type selfReferencingStruct struct {
+	Self *selfReferencingStruct
+}
+
+func TestAutowire_DetectsCycles(t *testing.T) {
+	cont := container.New()
+
+	err := cont.Autowire(&selfReferencingStruct{})
+	require.NoError(t, err)
+
+	_, err = cont.DetectCircularDependencies()
+	require.Error(t, err, "field types should be edges on the graph")
+}
+
+func TestAutowire_MissingDependency(t *testing.T) {
+	cont := container.New()
+
+	err := cont.Autowire(&autowiredService{})
+	require.NoError(t, err)
+
+	_, err = cont.DetectCircularDependencies()
+	require.Error(t, err, "missing field types should be detected when validating the graph")
+}
+
+type tokenCycle struct {
+	Next *bytes.Buffer `wiring:"next"`
+}
+
+type fillCycle struct {
+	Nested struct {
+		Buffer *bytes.Buffer
+	} `wiring:",fill"`
+}
+
+func TestAutowire_ContainerFieldsAreEdges(t *testing.T) {
+	cont := container.New()
+	err := cont.Autowire(&tokenCycle{})
+	require.NoError(t, err)
+
+	_, err = cont.DetectCircularDependencies()
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code(), "token fields should be validated")
+
+	cont.Token(map[string]any{
+		"next": func(*tokenCycle) *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		},
+	})
+	_, err = container.Resolve[*tokenCycle](cont)
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_CIRCULAR_DEPENDENCY, wiringErr.Code())
+
+	cont = container.New()
+	cont.Autowire(&fillCycle{})
+	cont.Transient(func(*fillCycle) *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+	_, err = container.Resolve[*fillCycle](cont)
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_CIRCULAR_DEPENDENCY, wiringErr.Code(), "fields of nested structs should be edges")
+}
+
+func TestAutowire_Graph(t *testing.T) {
+	cont := container.New()
+	cont.Token(map[string]any{
+		"buffer": func() *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		},
+	})
+	cont.Transient(testutils.NewService)
+	cont.Autowire(&autowiredService{})
+
+	g, err := cont.Graph()
+	require.NoError(t, err)
+
+	service, ok := g.Lookup(reflect.TypeFor[*autowiredService]())
+	require.True(t, ok)
+	buffer, ok := g.LookupToken("buffer")
+	require.True(t, ok)
+	require.Contains(t, service.Dependencies(), buffer)
+}
@@ -0,0 +1,180 @@
+package container
+
+import (
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// This file contains the logic of conditional registrations. Conditions
+// are evaluated when the graph is connected, so inactive resolvers never
+// become part of the graph.
+
+// ProfilesEnv is the environment variable read to get the active profiles
+// when they are not set with [Container.SetProfiles]. Profiles are
+// separated by commas.
+const ProfilesEnv = "WIRING_PROFILES"
+
+// Condition reports if a conditional resolver should be registered. It
+// receives a container derived from the one the resolver is registered on,
+// dependencies can be resolved from it while the conditionals are
+// evaluated.
+type Condition func(c *Container) bool
+
+// conditional is a resolver registered only when all its conditions are met
+type conditional struct {
+	resolver   any
+	conditions []Condition
+}
+
+// pendingConditional is a conditional registration not evaluated yet
+type pendingConditional struct {
+	module       string
+	registration registration
+	conditions   []Condition
+}
+
+// Conditional returns a resolver that is only registered when all the
+// conditions are met. It can be passed to any registration method of
+// [Container] and [Module].
+//
+//	cont.Singleton(
+//		container.Conditional(NewSMTPMailer, container.Profile("prod")),
+//		container.Conditional(NewFakeMailer, container.IfMissing[Mailer]()),
+//	)
+//
+// Conditions are evaluated in registration order the next time the graph is
+// connected, usually when the first dependency is resolved, and their
+// result is final. Registering several active resolvers for the same type
+// fails with [errors.E_REDECLARED_DEPENDENCY].
+func Conditional(res any, conditions ...Condition) any {
+	return &conditional{
+		resolver:   res,
+		conditions: conditions,
+	}
+}
+
+// When returns a condition that calls fn
+func When(fn func(c *Container) bool) Condition {
+	return fn
+}
+
+// Profile returns a condition met when any of the given profiles is active
+func Profile(profiles ...string) Condition {
+	return func(c *Container) bool {
+		active := c.Profiles()
+		for _, profile := range profiles {
+			if slices.Contains(active, profile) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// IfMissing returns a condition met when there is no resolver for T at the
+// time the condition is evaluated. Conditional resolvers registered before
+// are taken into account.
+func IfMissing[T any]() Condition {
+	return func(c *Container) bool {
+		return !c.canResolve(reflect.TypeFor[T]())
+	}
+}
+
+// SetProfiles sets the active profiles of this container and its derived
+// containers. It must be called before the conditional resolvers are
+// evaluated.
+func (c *Container) SetProfiles(profiles ...string) {
+	c.profiles = slices.Clone(profiles)
+	if c.profiles == nil {
+		c.profiles = []string{}
+	}
+}
+
+// Profiles returns the active profiles. Containers without profiles use the
+// ones of their parent, and the root container the ones of [ProfilesEnv].
+func (c *Container) Profiles() []string {
+	for current := c; current != nil; current = current.parent {
+		if current.profiles != nil {
+			return slices.Clone(current.profiles)
+		}
+	}
+
+	profiles := []string{}
+	for _, profile := range strings.Split(os.Getenv(ProfilesEnv), ",") {
+		profile = strings.TrimSpace(profile)
+		if profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles
+}
+
+// activateConditionals registers the conditional resolvers of this
+// container and its parents whose conditions are met. Conditions are
+// evaluated without holding the lock of the container and receive a
+// container derived from it, so a condition can resolve dependencies
+// without activating again the conditionals being evaluated.
+func (c *Container) activateConditionals() error {
+	if c.parent != nil && !c.evaluatingParent {
+		err := c.parent.activateConditionals()
+		if err != nil {
+			return err
+		}
+	}
+
+	c.activation.Lock()
+	defer c.activation.Unlock()
+
+	c.mu.Lock()
+	conditionals := slices.Clone(c.conditionals)
+	c.mu.Unlock()
+	if len(conditionals) == 0 {
+		return nil
+	}
+
+	view := c.Derived()
+	view.evaluatingParent = true
+	for i, pending := range conditionals {
+		if !pending.active(view) {
+			continue
+		}
+
+		r := pending.registration
+		r.resolver = r.resolver.(*conditional).resolver
+		c.mu.Lock()
+		_, err := c.register(pending.module, r)
+		if err != nil {
+			// the failed registration is kept so the error is
+			// reported again instead of resolving from a container
+			// with part of the registrations
+			c.conditionals = c.conditionals[i:]
+			c.mu.Unlock()
+			if pending.module != "" {
+				return moduleError(pending.module, err)
+			}
+			return err
+		}
+		c.mu.Unlock()
+	}
+
+	// conditions can register new conditionals
+	c.mu.Lock()
+	c.conditionals = c.conditionals[len(conditionals):]
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (p pendingConditional) active(c *Container) bool {
+	for _, condition := range p.conditions {
+		if !condition(c) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,148 @@
+package container_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func newProfiledContainer() (*container.Container, *bytes.Buffer, *bytes.Buffer) {
+	dev := bytes.NewBufferString("dev")
+	prod := bytes.NewBufferString("prod")
+
+	cont := container.New()
+	cont.Singleton(
+		container.Conditional(func() io.Writer {
+			return prod
+		}, container.Profile("prod")),
+		container.Conditional(func() io.Writer {
+			return dev
+		}, container.Profile("dev", "test")),
+	)
+
+	return cont, dev, prod
+}
+
+func TestConditional_Profile(t *testing.T) {
+	cont, _, prod := newProfiledContainer()
+	cont.SetProfiles("prod")
+
+	writer, err := container.Resolve[io.Writer](cont)
+	require.NoError(t, err)
+	require.Same(t, prod, writer)
+
+	g, err := cont.Graph()
+	require.NoError(t, err)
+	require.Len(t, g.Nodes(), 2, "inactive resolvers should not be part of the graph")
+}
+
+func TestConditional_ProfileFromEnv(t *testing.T) {
+	t.Setenv(container.ProfilesEnv, "local, test")
+	cont, dev, _ := newProfiledContainer()
+
+	require.Equal(t, []string{"local", "test"}, cont.Profiles())
+	writer, err := container.Resolve[io.Writer](cont)
+	require.NoError(t, err)
+	require.Same(t, dev, writer)
+
+	derived := container.New()
+	derived.SetProfiles("prod")
+	require.Equal(t, []string{"prod"}, derived.Derived().Profiles(), "profiles set from code should be used")
+}
+
+func TestConditional_NoActiveResolver(t *testing.T) {
+	cont, _, _ := newProfiledContainer()
+	cont.SetProfiles()
+
+	_, err := container.Resolve[io.Writer](cont)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+}
+
+func TestConditional_IfMissing(t *testing.T) {
+	cont := container.New()
+	cont.Transient(
+		container.Conditional(func() io.Writer {
+			return os.Stdout
+		}, container.When(func(c *container.Container) bool {
+			return true
+		})),
+		container.Conditional(func() io.Writer {
+			return os.Stderr
+		}, container.IfMissing[io.Writer]()),
+		container.Conditional(testutils.NewService, container.IfMissing[testutils.MyService]()),
+	)
+
+	writer, err := container.Resolve[io.Writer](cont)
+	require.NoError(t, err)
+	require.Same(t, os.Stdout, writer)
+
+	_, err = container.Resolve[testutils.MyService](cont)
+	require.NoError(t, err)
+}
+
+func TestConditional_Module(t *testing.T) {
+	cont := container.New()
+	cont.SetProfiles("prod")
+	err := cont.Install(container.NewModule("writers").Singleton(
+		container.Conditional(func() io.Writer {
+			return os.Stdout
+		}, container.Profile("prod")),
+		container.Conditional(func() io.Writer {
+			return os.Stderr
+		}, container.Profile("prod")),
+	))
+	require.NoError(t, err)
+
+	_, err = container.Resolve[io.Writer](cont)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_REDECLARED_DEPENDENCY, wiringErr.Code())
+	require.Contains(t, err.Error(), "module 'writers'")
+
+	_, err = container.Resolve[io.Writer](cont)
+	require.ErrorAs(t, err, &wiringErr, "the error should be reported until it's fixed")
+	require.Equal(t, wiringerrors.E_REDECLARED_DEPENDENCY, wiringErr.Code())
+}
+
+type featureConfig struct {
+	Enabled bool
+}
+
+func TestConditional_ResolvesConfig(t *testing.T) {
+	cont := container.New()
+	cont.Singleton(func() *featureConfig {
+		return &featureConfig{Enabled: true}
+	})
+	cont.Transient(
+		container.Conditional(func() io.Writer {
+			return os.Stdout
+		}, container.When(func(c *container.Container) bool {
+			cfg, err := container.Resolve[*featureConfig](c)
+			return err == nil && cfg.Enabled
+		})),
+		container.Conditional(testutils.NewService, container.When(func(c *container.Container) bool {
+			// the conditional being evaluated is ignored
+			_, err := container.Resolve[testutils.MyService](c)
+			return err != nil
+		})),
+	)
+
+	writer, err := container.Resolve[io.Writer](cont)
+	require.NoError(t, err)
+	require.Same(t, os.Stdout, writer)
+
+	_, err = container.Resolve[testutils.MyService](cont)
+	require.NoError(t, err)
+
+	cfg, err := container.Resolve[*featureConfig](cont)
+	require.NoError(t, err)
+	require.True(t, cfg.Enabled)
+}
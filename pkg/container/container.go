@@ -2,6 +2,9 @@ package container
 
 import (
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/4strodev/wiring_graphs/pkg/errors"
 	"github.com/4strodev/wiring_graphs/pkg/internal/collections/graph"
@@ -9,27 +12,76 @@ import (
 )
 
 type resolverConfig struct {
-	singleton  bool
+	singleton bool
+	node      *graph.Node[resolver.DependencyResolver[any]]
+	// mu guards resolved and savedValue so singletons are built once when
+	// they are resolved concurrently
+	mu         sync.Mutex
 	resolved   bool
-	node       *graph.Node[resolver.DependencyResolver[any]]
 	savedValue reflect.Value
+	// declaredType is the type a [Key] was registered with, nil for
+	// plain string tokens and types
+	declaredType reflect.Type
+	// token the resolver was registered with, empty for types
+	token string
+	// module that registered the resolver, empty when it was registered
+	// directly on the container
+	module string
+	// private resolvers can only be injected into resolvers of the same
+	// module
+	private bool
+	// plan is compiled once the graph is connected
+	plan *resolutionPlan
+	// dependencies resolved through the container by resolvers built with
+	// [Struct], they are connected like the inputs of the resolver
+	dependencies []implicitDependency
 }
 
 type Container struct {
-	parent     *Container
+	parent *Container
+	// mu guards the lazy connection of the graph, containers derived
+	// concurrently connect their parents from different goroutines
+	mu         sync.Mutex
 	graph      graph.Graph[resolver.DependencyResolver[any]]
 	typeIndex  map[reflect.Type]*resolverConfig
 	tokenIndex map[string]*resolverConfig
-	connected  bool
+	// privateIndex holds the private resolvers of each module
+	privateIndex map[string]map[reflect.Type]*resolverConfig
+	// pending holds the resolvers registered since the last time the
+	// graph was connected
+	pending []*resolverConfig
+	// modules installed on this container by name
+	modules map[string]*Module
+	// conditionals holds the conditional registrations not evaluated yet
+	conditionals []pendingConditional
+	// activation serializes the evaluation of conditionals
+	activation sync.Mutex
+	// evaluatingParent is set on the container given to the conditions of
+	// its parent. Resolving from it doesn't activate nor connect the
+	// parent again.
+	evaluatingParent bool
+	// profiles active on this container, nil to use the ones of the
+	// parent or the environment
+	profiles []string
+	// properties are used to resolve tokens without resolver
+	properties []PropertySource
+	// observers are notified each time a dependency is resolved
+	observers []func(ResolveEvent)
+	// built holds the singletons of this container in the order they
+	// were built, guarded by builtMu
+	built   []*resolverConfig
+	builtMu sync.Mutex
 }
 
 // Retuns a new container and sets a default dependency that allows
 // to inject this container as a parameter on the resolvers
 func New() *Container {
 	container := &Container{
-		graph:      graph.NewGraph[resolver.DependencyResolver[any]](),
-		typeIndex:  make(map[reflect.Type]*resolverConfig),
-		tokenIndex: make(map[string]*resolverConfig),
+		graph:        graph.NewGraph[resolver.DependencyResolver[any]](),
+		typeIndex:    make(map[reflect.Type]*resolverConfig),
+		tokenIndex:   make(map[string]*resolverConfig),
+		privateIndex: make(map[string]map[reflect.Type]*resolverConfig),
+		modules:      make(map[string]*Module),
 	}
 
 	// Allow resolvers to inject container
@@ -53,90 +105,135 @@ func (c *Container) Must() *MustContainer {
 }
 
 func (c *Container) Transient(resolvers ...any) error {
-	c.connected = false
 	for _, res := range resolvers {
-		config, err := buildConfig(res)
+		_, err := c.register("", registration{resolver: res})
 		if err != nil {
 			return err
 		}
-
-		resType := config.node.Val.Type()
-		_, exists := c.typeIndex[resType]
-		if exists {
-			return errors.Errorf(errors.E_REDECLARED_DEPENDENCY, "dependency for this type already exists: %v", resType)
-		}
-
-		c.graph.Add(config.node)
-		c.typeIndex[resType] = config
 	}
 
 	return nil
 }
 
 func (c *Container) Singleton(resolvers ...any) error {
-	c.connected = false
 	for _, res := range resolvers {
-		config, err := buildConfig(res)
+		_, err := c.register("", registration{resolver: res, singleton: true})
 		if err != nil {
 			return err
 		}
-
-		resType := config.node.Val.Type()
-		_, exists := c.typeIndex[resType]
-		if exists {
-			return errors.Errorf(errors.E_REDECLARED_DEPENDENCY, "dependency for this type already exists: %v", resType)
-		}
-
-		c.graph.Add(config.node)
-		config.singleton = true
-		c.typeIndex[resType] = config
 	}
 
 	return nil
 }
 
 func (c *Container) TokenSingleton(dependencies map[string]any) error {
-	c.connected = false
 	for token, res := range dependencies {
-		config, err := buildConfig(res)
+		_, err := c.register("", registration{resolver: res, token: token, singleton: true})
 		if err != nil {
 			return err
 		}
-
-		_, exists := c.tokenIndex[token]
-		if exists {
-			return errors.Errorf(errors.E_REDECLARED_DEPENDENCY, "dependency for token type already exists: %s", token)
-		}
-
-		c.graph.Add(config.node)
-		config.singleton = true
-		c.tokenIndex[token] = config
 	}
 
 	return nil
 }
 
 func (c *Container) Token(dependencies map[string]any) error {
-	c.connected = false
 	for token, res := range dependencies {
-		config, err := buildConfig(res)
+		_, err := c.register("", registration{resolver: res, token: token})
 		if err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
+
+// addType registers res under the type it returns
+func (c *Container) addType(res any, singleton bool) (*resolverConfig, error) {
+	config, err := buildConfig(res)
+	if err != nil {
+		return nil, err
+	}
+
+	resType := config.node.Val.Type()
+	_, exists := c.typeIndex[resType]
+	if exists {
+		return nil, errors.Errorf(errors.E_REDECLARED_DEPENDENCY, "dependency for this type already exists: %v", resType)
+	}
 
-		_, exists := c.tokenIndex[token]
-		if exists {
-			return errors.Errorf(errors.E_REDECLARED_DEPENDENCY, "dependency for token already exists: %s", token)
+	c.addNode(config)
+	config.singleton = singleton
+	c.typeIndex[resType] = config
+
+	return config, nil
+}
+
+// addToken registers res under token. When declaredType is not nil the
+// resolver return type must be assignable to it and resolved values are
+// converted to that type.
+func (c *Container) addToken(token string, res any, singleton bool, declaredType reflect.Type) (*resolverConfig, error) {
+	config, err := buildConfig(res)
+	if err != nil {
+		return nil, err
+	}
+
+	if declaredType != nil {
+		resType := config.node.Val.Type()
+		if !resType.AssignableTo(declaredType) {
+			return nil, errors.Errorf(errors.E_TYPE_ERROR, "resolver for token '%s' returns %v which is not assignable to %v", token, resType, declaredType)
 		}
+	}
 
-		c.graph.Add(config.node)
-		c.tokenIndex[token] = config
+	_, exists := c.tokenIndex[token]
+	if exists {
+		return nil, errors.Errorf(errors.E_REDECLARED_DEPENDENCY, "dependency for token already exists: %s", token)
 	}
 
-	return nil
+	c.addNode(config)
+	config.singleton = singleton
+	config.declaredType = declaredType
+	config.token = token
+	c.tokenIndex[token] = config
+
+	return config, nil
+}
+
+// addNode adds the node of config to the graph. The node is connected the
+// next time a dependency is resolved.
+func (c *Container) addNode(config *resolverConfig) {
+	c.graph.Add(config.node)
+	c.pending = append(c.pending, config)
+}
+
+// configsByNode returns the resolvers registered on this container indexed
+// by their node
+func (c *Container) configsByNode() map[*graph.Node[resolver.DependencyResolver[any]]]*resolverConfig {
+	configs := map[*graph.Node[resolver.DependencyResolver[any]]]*resolverConfig{}
+	for _, config := range c.typeIndex {
+		configs[config.node] = config
+	}
+	for _, config := range c.tokenIndex {
+		configs[config.node] = config
+	}
+	for _, private := range c.privateIndex {
+		for _, config := range private {
+			configs[config.node] = config
+		}
+	}
+
+	return configs
 }
 
 func buildConfig(res any) (*resolverConfig, error) {
+	var dependencies []implicitDependency
+	if synthesized, ok := res.(*autowired); ok {
+		if synthesized.err != nil {
+			return nil, synthesized.err
+		}
+		res = synthesized.resolver
+		dependencies = synthesized.dependencies
+	}
+
 	if !resolver.IsValid(res) {
 		return nil, errors.Errorf(errors.E_INVALID_RESOLVER, "Invalid resolver")
 	}
@@ -147,14 +244,33 @@ func buildConfig(res any) (*resolverConfig, error) {
 
 	node := graph.NewNode(builder)
 	config := resolverConfig{
-		node: node,
+		node:         node,
+		dependencies: dependencies,
 	}
 
 	return &config, nil
 }
 
+// ensureNodesConnected connects the resolvers registered since the last
+// time on this container and its parents. Dependencies resolved by a
+// parent are not connected on this container, so parents are connected
+// first.
 func (c *Container) ensureNodesConnected() error {
-	if c.connected {
+	if c.parent != nil && !c.evaluatingParent {
+		err := c.parent.ensureNodesConnected()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.activateConditionals()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) == 0 {
 		return nil
 	}
 
@@ -174,140 +290,332 @@ func (c *Container) DetectCircularDependencies() ([]*graph.Node[resolver.Depende
 	return nil, nil
 }
 
-func (c Container) getNodeFor(t reflect.Type) (*graph.Node[resolver.DependencyResolver[any]], error) {
-	node, ok := c.typeIndex[t]
-	if !ok {
-		return nil, errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency for %v not fonud", t)
+// localConfig returns the resolver of this container injected for t into
+// the resolvers of module. Private resolvers of the module hide the rest.
+func (c *Container) localConfig(module string, t reflect.Type) *resolverConfig {
+	config, ok := c.privateIndex[module][t]
+	if ok {
+		return config
+	}
+
+	return c.typeIndex[t]
+}
+
+// canResolve reports if this container or any of its parents has a
+// resolver for t
+func (c *Container) canResolve(t reflect.Type) bool {
+	for current := c; current != nil; current = current.parent {
+		_, ok := current.typeIndex[t]
+		if ok {
+			return true
+		}
 	}
 
-	return node.node, nil
+	_, isDeferred := asDeferred(t)
+	return isDeferred
 }
 
-// setConnections stablishes connections between nodes and
-// look for circular dependencies
+// setConnections stablishes connections between the pending nodes and
+// their dependencies and look for circular dependencies. Since the graph had
+// no cycles before, any new cycle goes through a pending node so only them
+// are checked.
 func (c *Container) setConnections() error {
-	for node := range c.graph.GetNodes() {
-		resType := reflect.TypeOf(node.Val.Resolver)
-		for i := 0; i < resType.NumIn(); i++ {
-			dependencyType := resType.In(i)
-
-			if node.Val.Type() == dependencyType {
-				return errors.Errorf(
-					errors.E_CIRCULAR_DEPENDENCY,
-					"circular dependency found: %v",
-					[]*graph.Node[resolver.DependencyResolver[any]]{
-						node,
-					})
-			}
-
-			dependencyNode, err := c.getNodeFor(dependencyType)
-			if err != nil {
-				return err
-			}
-
-			if dependencyNode.IsConnectedWith(node) {
-				return errors.Errorf(
-					errors.E_CIRCULAR_DEPENDENCY,
-					"circular dependency found: %v",
-					[]*graph.Node[resolver.DependencyResolver[any]]{
-						node,
-						dependencyNode,
-						node,
-					},
-				)
-			}
-
-			c.graph.Connect(dependencyNode, node, graph.OUT)
+	c.requeueShadowing()
+
+	nodes := make([]*graph.Node[resolver.DependencyResolver[any]], 0, len(c.pending))
+	for _, config := range c.pending {
+		err := c.connect(config)
+		if err != nil {
+			return config.wrapError(err)
+		}
+
+		nodes = append(nodes, config.node)
+	}
+
+	// optional dependencies of resolvers connected before can be
+	// resolved by the pending ones
+	for _, config := range c.configsByNode() {
+		if len(config.dependencies) == 0 || slices.Contains(c.pending, config) {
+			continue
+		}
+
+		err := c.connectOptional(config)
+		if err != nil {
+			return config.wrapError(err)
 		}
 	}
 
-	cicle, hasCicle := c.graph.DetectCircularRelations()
+	cicle, hasCicle := c.graph.DetectCircularRelationsFrom(nodes...)
 	if hasCicle {
 		return errors.Errorf(errors.E_CIRCULAR_DEPENDENCY, "circular dependency found: %v", cicle)
 	}
 
-	c.connected = true
+	for _, config := range c.pending {
+		config.plan = c.compilePlan(config)
+	}
+	c.pending = nil
+
 	return nil
 }
 
-func (c Container) resolve(t reflect.Type) (resolvedValue reflect.Value, err error) {
-	node, ok := c.typeIndex[t]
-	if !ok {
-		if c.parent == nil {
-			err = errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency not found for type %v", t)
-			return
-		}
-		return c.parent.resolve(t)
+// requeueShadowing adds to pending the resolvers that were connected
+// without an edge because a parent resolved one of their dependencies, when
+// a pending resolver of this container hides that parent resolver now.
+// Values saved by them and by their dependents were built with the parent
+// resolver so they are released.
+func (c *Container) requeueShadowing() {
+	if c.parent == nil {
+		return
 	}
 
-	if node.singleton && node.resolved {
-		resolvedValue = node.savedValue
+	shadowing := []*resolverConfig{}
+	for _, config := range c.pending {
+		if config.token == "" && c.parent.canResolve(config.node.Val.Type()) {
+			shadowing = append(shadowing, config)
+		}
+	}
+	if len(shadowing) == 0 {
 		return
 	}
 
-	dependencyResolver := node.node.Val
-	inputTypes := dependencyResolver.Input()
-	inputArgs := []reflect.Value{}
-
-	for _, inputType := range inputTypes {
-		arg, err := c.resolve(inputType)
-		if err != nil {
-			return resolvedValue, err
+	configs := c.configsByNode()
+	for _, config := range configs {
+		if slices.Contains(c.pending, config) || !c.dependsOnAny(config, shadowing) {
+			continue
 		}
 
-		inputArgs = append(inputArgs, arg)
+		c.pending = append(c.pending, config)
+		c.release(config, configs)
 	}
+}
 
-	resolvedValue, err = resolver.Execute(dependencyResolver, inputArgs)
-	if err != nil {
-		return
+// dependsOnAny reports if any of configs is injected into config by type
+func (c *Container) dependsOnAny(config *resolverConfig, configs []*resolverConfig) bool {
+	types := config.node.Val.Input()
+	for _, dependency := range config.dependencies {
+		if dependency.token == "" {
+			types = append(types, dependency.t)
+		}
 	}
-	node.resolved = true
 
-	if node.singleton {
-		node.savedValue = resolvedValue
+	for _, t := range types {
+		if slices.Contains(configs, c.localConfig(config.module, t)) {
+			return true
+		}
 	}
 
-	return
+	return false
 }
 
-func (c Container) resolveToken(token string) (resolvedValue reflect.Value, err error) {
-	node, ok := c.tokenIndex[token]
-	if !ok {
-		if c.parent == nil {
-			err = errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency not found for token '%s'", token)
-			return
+// release drops the values saved by config and by the resolvers that
+// depend on it
+func (c *Container) release(config *resolverConfig, configs map[*graph.Node[resolver.DependencyResolver[any]]]*resolverConfig) {
+	nodes := []*graph.Node[resolver.DependencyResolver[any]]{config.node}
+	for len(nodes) > 0 {
+		node := nodes[len(nodes)-1]
+		nodes = nodes[:len(nodes)-1]
+
+		dependent := configs[node]
+		if dependent == nil || (!dependent.resolved && node != config.node) {
+			continue
 		}
-		return c.parent.resolveToken(token)
+
+		dependent.resolved = false
+		dependent.savedValue = reflect.Value{}
+		nodes = append(nodes, node.GetOutgoingNodes()...)
 	}
+}
 
-	if node.singleton && node.resolved {
-		resolvedValue = node.savedValue
-		return
+// connect connects the node of config with the nodes of its dependencies
+func (c *Container) connect(config *resolverConfig) error {
+	for _, dependencyType := range config.node.Val.Input() {
+		err := c.connectType(config, dependencyType)
+		if err != nil {
+			return err
+		}
 	}
 
-	dependencyResolver := node.node.Val
-	inputTypes := dependencyResolver.Input()
-	inputArgs := []reflect.Value{}
+	for _, dependency := range config.dependencies {
+		if dependency.optional {
+			continue
+		}
 
-	for _, inputType := range inputTypes {
-		arg, err := c.resolve(inputType)
+		var err error
+		if dependency.token != "" {
+			err = c.connectToken(config, dependency)
+		} else {
+			err = c.connectType(config, dependency.t)
+		}
 		if err != nil {
-			return resolvedValue, err
+			return err
 		}
+	}
+
+	return c.connectOptional(config)
+}
 
-		inputArgs = append(inputArgs, arg)
+// connectOptional connects the node of config with the resolvers of its
+// optional dependencies that can be resolved
+func (c *Container) connectOptional(config *resolverConfig) error {
+	for _, dependency := range config.dependencies {
+		if !dependency.optional || !c.canResolve(dependency.t) {
+			continue
+		}
+
+		err := c.connectType(config, dependency.t)
+		if err != nil {
+			return err
+		}
 	}
 
-	resolvedValue, err = resolver.Execute(dependencyResolver, inputArgs)
-	if err != nil {
+	return nil
+}
+
+// connectType connects the node of config with the resolver injected for
+// dependencyType
+func (c *Container) connectType(config *resolverConfig, dependencyType reflect.Type) error {
+	node := config.node
+
+	// deferred dependencies are built after the resolver is
+	// called so they don't create an edge on the graph
+	deferred, isDeferred := asDeferred(dependencyType)
+	if isDeferred {
+		if !c.canResolve(deferred.dependencyType()) {
+			return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency for %v not found", deferred.dependencyType())
+		}
+		return nil
+	}
+
+	if node.Val.Type() == dependencyType {
+		return errors.Errorf(
+			errors.E_CIRCULAR_DEPENDENCY,
+			"circular dependency found: %v",
+			[]*graph.Node[resolver.DependencyResolver[any]]{
+				node,
+			})
+	}
+
+	// parents can't depend on their children so dependencies
+	// resolved by a parent can't be part of a cycle
+	dependency := c.localConfig(config.module, dependencyType)
+	if dependency == nil && c.parent != nil && c.parent.canResolve(dependencyType) {
+		return nil
+	}
+
+	if dependency == nil {
+		return c.errNotFound(dependencyType)
+	}
+
+	return c.connectNodes(node, dependency.node)
+}
+
+// connectToken connects the node of config with the resolver of the token
+// of dependency. Tokens resolved by a parent or by a property source are
+// only validated.
+func (c *Container) connectToken(config *resolverConfig, dependency implicitDependency) error {
+	dependencyType := dependency.t
+	deferred, isDeferred := asDeferred(dependencyType)
+	if isDeferred {
+		dependencyType = deferred.dependencyType()
+	}
+
+	tokenConfig, isLocal := c.tokenIndex[dependency.token]
+	if !isLocal || isDeferred {
+		if !c.canResolveToken(dependency.token, dependencyType) {
+			return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency not found for token '%s'", dependency.token)
+		}
+		return nil
+	}
+
+	return c.connectNodes(config.node, tokenConfig.node)
+}
+
+// connectNodes adds an edge from dependencyNode to node
+func (c *Container) connectNodes(node, dependencyNode *graph.Node[resolver.DependencyResolver[any]]) error {
+	if node == dependencyNode {
+		return errors.Errorf(
+			errors.E_CIRCULAR_DEPENDENCY,
+			"circular dependency found: %v",
+			[]*graph.Node[resolver.DependencyResolver[any]]{
+				node,
+			})
+	}
+
+	// a single connection is kept between two nodes so a
+	// dependency in the opposite direction is a cycle
+	if slices.Contains(node.GetOutgoingNodes(), dependencyNode) {
+		return errors.Errorf(
+			errors.E_CIRCULAR_DEPENDENCY,
+			"circular dependency found: %v",
+			[]*graph.Node[resolver.DependencyResolver[any]]{
+				node,
+				dependencyNode,
+				node,
+			},
+		)
+	}
+
+	c.graph.Connect(dependencyNode, node, graph.OUT)
+	return nil
+}
+
+func (c *Container) resolve(t reflect.Type, chain *resolution) (resolvedValue reflect.Value, err error) {
+	deferred, isDeferred := asDeferred(t)
+	if isDeferred {
+		return c.resolveDeferred(deferred, "", chain), nil
+	}
+
+	node, ok := c.typeIndex[t]
+	if !ok {
+		if c.parent == nil {
+			err = c.errNotFound(t)
+			return
+		}
+		resolvedValue, err = c.parent.resolve(t, chain)
+		if err == nil {
+			c.notify(ResolveEvent{Type: t})
+		}
 		return
 	}
-	node.resolved = true
 
-	if node.singleton {
-		node.savedValue = resolvedValue
+	return c.resolveConfig(node, chain)
+}
+
+// errNotFound returns the error for a type without resolver. When t is
+// private to some modules the error lists them.
+func (c *Container) errNotFound(t reflect.Type) error {
+	modules := []string{}
+	for module, private := range c.privateIndex {
+		_, ok := private[t]
+		if ok {
+			modules = append(modules, "'"+module+"'")
+		}
+	}
+	slices.Sort(modules)
+
+	switch len(modules) {
+	case 0:
+		return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency not found for type %v", t)
+	case 1:
+		return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency not found for type %v, it's private to module %s", t, modules[0])
+	default:
+		return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency not found for type %v, it's private to modules %s", t, strings.Join(modules, ", "))
+	}
+}
+
+func (c *Container) resolveToken(token string, chain *resolution) (resolvedValue reflect.Value, err error) {
+	node, ok := c.tokenIndex[token]
+	if !ok {
+		if c.parent == nil {
+			err = errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency not found for token '%s'", token)
+			return
+		}
+		resolvedValue, err = c.parent.resolveToken(token, chain)
+		if err == nil {
+			c.notify(ResolveEvent{Type: resolvedValue.Type(), Token: token})
+		}
+		return
 	}
 
-	return
+	return c.resolveConfig(node, chain)
 }
@@ -3,13 +3,61 @@ package container
 import (
 	"reflect"
 	"strings"
+	"unsafe"
 
 	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/set"
 )
 
 // This file contains all the logic related to automatically fill structs
 
-func (c Container) Fill(structPointer any) (err error) {
+// fieldInjection describes how a struct field is filled
+type fieldInjection struct {
+	index int
+	token string
+	// fill requests to fill the field as a nested struct instead of
+	// resolving it
+	fill bool
+	// private allows to fill an unexported field
+	private bool
+	field   reflect.StructField
+}
+
+// FillMode sets which fields [Container.FillWith] requires to be resolved
+type FillMode int
+
+const (
+	// FillRequired requires every exported field not tagged with omit to be
+	// resolved. Unexported fields are skipped.
+	FillRequired FillMode = iota
+	// FillStrict works like [FillRequired] but also fails on unexported
+	// fields not tagged with omit, since they cannot be filled.
+	FillStrict
+	// FillLenient skips the fields that have no resolver
+	FillLenient
+)
+
+// FillOptions customizes how [Container.FillWith] fills a struct
+type FillOptions struct {
+	// Recursive fills nested structs, struct pointers and embedded structs
+	// that have no resolver. Nil struct pointers are allocated.
+	Recursive bool
+	// Mode sets which fields must be resolved
+	Mode FillMode
+	// CollectErrors keeps filling the remaining fields when one fails and
+	// returns all the failures joined with code [errors.E_MULTIPLE_ERRORS]
+	CollectErrors bool
+}
+
+// Fill resolves the exported fields of the struct pointed by structPointer and
+// the unexported ones tagged with private.
+// It's equivalent to call [Container.FillWith] with the default options.
+func (c *Container) Fill(structPointer any) (err error) {
+	return c.FillWith(structPointer, FillOptions{})
+}
+
+// FillWith resolves the fields of the struct pointed by structPointer
+func (c *Container) FillWith(structPointer any, options FillOptions) (err error) {
 	refStructValue := reflect.ValueOf(structPointer)
 	if refStructValue.Kind() != reflect.Pointer {
 		return errors.Errorf(errors.E_TYPE_ERROR, "fill expects a struct pointer '%v' was given", refStructValue.Kind())
@@ -19,72 +67,292 @@ func (c Container) Fill(structPointer any) (err error) {
 		return errors.Errorf(errors.E_TYPE_ERROR, "fill expects a struct pointer '%v' pointer was given", refStructValue.Elem().Kind())
 	}
 
+	err = c.activateConditionals()
+	if err != nil {
+		return err
+	}
+
 	refStructType := refStructValue.Elem().Type()
+	f := newFiller(c, options)
+	err = f.fillStruct(refStructValue.Elem(), refStructType.Name(), true)
+	if err != nil {
+		return err
+	}
 
-	for i := 0; i < refStructValue.Elem().NumField(); i++ {
-		fieldValue := refStructValue.Elem().Field(i)
-		if !fieldValue.CanSet() {
-			continue
-		}
+	return errors.Join(f.failures...)
+}
 
-		var instance reflect.Value
+// filler holds the state of a single fill operation
+type filler struct {
+	container *Container
+	options   FillOptions
+	// visiting holds the struct types being filled on the current path
+	visiting set.Set[reflect.Type]
+	// failures collected when options.CollectErrors is set
+	failures []error
+}
 
-		wiringTag := refStructType.Field(i).Tag.Get("wiring")
-		if isOmitted(wiringTag) {
-			continue
-		}
-		token := getToken(wiringTag)
+func newFiller(c *Container, options FillOptions) *filler {
+	return &filler{
+		container: c,
+		options:   options,
+		visiting:  set.New[reflect.Type](),
+	}
+}
 
-		if token != "" {
-			instance, err = c.resolveToken(token)
-		} else {
-			instance, err = c.resolve(fieldValue.Type())
-		}
-		if err != nil {
-			// Wrap error
-			customError, ok := err.(*errors.WiringError)
-			if !ok {
+// fail records err when errors are collected, otherwise returns it
+// to stop filling
+func (f *filler) fail(err error) error {
+	if !f.options.CollectErrors {
+		return err
+	}
+
+	f.failures = append(f.failures, err)
+	return nil
+}
+
+// fillStruct fills the fields of structValue. path is the chain of field
+// names used to reach structValue. When hooks is false the setters and
+// hooks of the struct are not called, embedded structs are filled this way
+// since their methods are part of the method set of the struct that
+// embeds them.
+func (f *filler) fillStruct(structValue reflect.Value, path string, hooks bool) error {
+	structType := structValue.Type()
+	f.visiting.Add(structType)
+	defer f.visiting.Remove(structType)
+
+	if f.options.Mode == FillStrict {
+		for _, field := range unfillableFields(structType) {
+			err := f.fail(errors.Errorf(errors.E_TYPE_ERROR, "cannot fill unexported field %s.%s", path, field.Name))
+			if err != nil {
 				return err
 			}
-			return errors.Errorf(
-				customError.Code(),
-				"cannot resolve dependency for field %s.%s: %w",
-				refStructType.Name(),
-				refStructType.Field(i).Name,
-				customError)
 		}
+	}
+
+	failures := len(f.failures)
+	for _, injection := range injectableFields(structType) {
+		err := f.fillField(structValue.Field(injection.index), path, injection)
+		if err != nil {
+			return err
+		}
+	}
+
+	// hooks expect every dependency to be injected
+	if !hooks || len(f.failures) > failures {
+		return nil
+	}
+
+	return f.afterFill(structValue, path)
+}
+
+// fillField fills a single field of the struct reached by path
+func (f *filler) fillField(fieldValue reflect.Value, path string, injection fieldInjection) error {
+	fieldValue = settable(fieldValue, injection)
 
-		fieldValue.Set(instance)
+	if f.isNestedFill(injection) {
+		return f.fillNested(fieldValue, path+"."+injection.field.Name, !injection.field.Anonymous)
 	}
 
+	if f.options.Mode == FillLenient && !f.container.canResolveField(injection) {
+		return nil
+	}
+
+	instance, err := f.container.resolveField(path, injection)
+	if err != nil {
+		return f.fail(err)
+	}
+
+	fieldValue.Set(instance)
 	return nil
 }
 
-// returns the token that the tag is requesting
-// only returns a token when it's found and tag is well
-// written. On any other case it just returns an empty string
-func getToken(tagValue string) string {
-	if tagValue == "" {
-		return tagValue
+// isNestedFill reports if the field should be filled as a nested struct
+func (f *filler) isNestedFill(injection fieldInjection) bool {
+	if injection.fill {
+		return true
 	}
 
-	tagSegments := strings.Split(tagValue, ",")
-	// possible values
-	// wiring:"tokenName" -> token required
-	// wiring:"tokenName,omit" -> token not required
-	// wiring:",omit" -> token not required
-	if len(tagSegments) > 2 {
-		return ""
+	if !f.options.Recursive || injection.token != "" {
+		return false
+	}
+
+	fieldType := injection.field.Type
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() != reflect.Struct {
+		return false
 	}
 
-	if len(tagSegments) == 2 && tagSegments[1] == "omit" {
-		return ""
+	return !f.container.canResolve(injection.field.Type)
+}
+
+// fillNested fills a struct or struct pointer field allocating it
+// when it's nil
+func (f *filler) fillNested(fieldValue reflect.Value, path string, hooks bool) error {
+	switch {
+	case fieldValue.Kind() == reflect.Struct:
+		return f.fillStruct(fieldValue, path, hooks)
+	case fieldValue.Kind() == reflect.Pointer && fieldValue.Type().Elem().Kind() == reflect.Struct:
+		elemType := fieldValue.Type().Elem()
+		if f.visiting.Has(elemType) {
+			return f.fail(errors.Errorf(errors.E_CIRCULAR_DEPENDENCY, "cannot fill field %s: %v references itself", path, elemType))
+		}
+
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(elemType))
+		}
+		return f.fillStruct(fieldValue.Elem(), path, hooks)
+	default:
+		return f.fail(errors.Errorf(errors.E_TYPE_ERROR, "cannot fill field %s: %v is not a struct or a struct pointer", path, fieldValue.Type()))
+	}
+}
+
+// injectableFields returns the fields of structType that should be filled
+func injectableFields(structType reflect.Type) []fieldInjection {
+	fields := []fieldInjection{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := parseTag(field.Tag.Get("wiring"))
+		if tag.omit || (!field.IsExported() && !tag.private) {
+			continue
+		}
+
+		fields = append(fields, fieldInjection{
+			index:   i,
+			token:   tag.token,
+			fill:    tag.fill,
+			private: tag.private,
+			field:   field,
+		})
+	}
+
+	return fields
+}
+
+// settable returns a settable view of an unexported field tagged with
+// private. Any other field is returned as is.
+//
+// Setting unexported fields bypasses the visibility rules of Go using
+// unsafe. The field must be reachable from a pointer, which is always the
+// case for fill since it receives a struct pointer.
+func settable(fieldValue reflect.Value, injection fieldInjection) reflect.Value {
+	if !injection.private || fieldValue.CanSet() {
+		return fieldValue
+	}
+
+	return reflect.NewAt(fieldValue.Type(), unsafe.Pointer(fieldValue.UnsafeAddr())).Elem()
+}
+
+// unfillableFields returns the unexported fields of structType that are not
+// tagged with omit or private
+func unfillableFields(structType reflect.Type) []reflect.StructField {
+	fields := []reflect.StructField{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := parseTag(field.Tag.Get("wiring"))
+		if field.IsExported() || tag.omit || tag.private {
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// canResolveField reports if there is a resolver for the field
+func (c *Container) canResolveField(injection fieldInjection) bool {
+	if injection.token == "" {
+		return c.canResolve(injection.field.Type)
 	}
 
-	return strings.Trim(tagSegments[0], " \n\t\r\f")
+	return c.canResolveToken(injection.token, injection.field.Type)
 }
 
-func isOmitted(tagValue string) bool {
+// resolveField resolves the value for a field of the struct reached by path
+func (c *Container) resolveField(path string, injection fieldInjection) (instance reflect.Value, err error) {
+	fieldType := injection.field.Type
+
+	deferred, isDeferred := asDeferred(fieldType)
+	if isDeferred {
+		instance = c.resolveDeferred(deferred, injection.token, nil)
+	} else if injection.token != "" {
+		instance, err = c.resolveTokenAs(injection.token, fieldType, nil)
+	} else {
+		instance, err = c.resolve(fieldType, nil)
+	}
+	if err != nil {
+		// Wrap error
+		customError, ok := err.(*errors.WiringError)
+		if !ok {
+			return instance, err
+		}
+		return instance, errors.Errorf(
+			customError.Code(),
+			"cannot resolve dependency for field %s.%s: %w",
+			path,
+			injection.field.Name,
+			customError)
+	}
+
+	if !instance.Type().AssignableTo(fieldType) {
+		return instance, errors.Errorf(
+			errors.E_TYPE_ERROR,
+			"cannot assign %v to field %s.%s of type %v",
+			instance.Type(),
+			path,
+			injection.field.Name,
+			fieldType)
+	}
+
+	return instance, nil
+}
+
+// wiringTag holds the parsed value of a wiring tag
+type wiringTag struct {
+	token   string
+	omit    bool
+	fill    bool
+	private bool
+}
+
+// parseTag parses a wiring tag. The first segment is the token and the
+// rest are options.
+//
+//	wiring:"tokenName" -> token required
+//	wiring:"tokenName,omit" -> token not required
+//	wiring:",omit" -> token not required
+//	wiring:",fill" -> filled as a nested struct
+//	wiring:",private" -> unexported field filled
+func parseTag(tagValue string) wiringTag {
 	tagSegments := strings.Split(tagValue, ",")
-	return len(tagSegments) == 2 && tagSegments[1] == "omit"
+	tag := wiringTag{
+		token: strings.Trim(tagSegments[0], " \n\t\r\f"),
+	}
+
+	for _, option := range tagSegments[1:] {
+		switch strings.TrimSpace(option) {
+		case "omit":
+			tag.omit = true
+		case "fill":
+			tag.fill = true
+		case "private":
+			tag.private = true
+		}
+	}
+
+	if tag.omit {
+		tag.token = ""
+	}
+
+	return tag
+}
+
+// returns the token that the tag is requesting
+// only returns a token when it's found and tag is well
+// written. On any other case it just returns an empty string
+func getToken(tagValue string) string {
+	return parseTag(tagValue).token
 }
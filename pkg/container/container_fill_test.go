@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/4strodev/wiring_graphs/pkg/errors"
 	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -64,3 +65,210 @@ func TestGetToken(t *testing.T) {
 		})
 	}
 }
+
+type nestedLogger struct {
+	Buffer *bytes.Buffer `wiring:"buffer"`
+}
+
+type nestedRouter struct {
+	Logger *nestedLogger `wiring:",fill"`
+}
+
+type nestedHTTP struct {
+	Router nestedRouter `wiring:",fill"`
+}
+
+type nestedApp struct {
+	HTTP *nestedHTTP `wiring:",fill"`
+}
+
+func TestFill_NestedTag(t *testing.T) {
+	cont := New()
+	cont.Token(map[string]any{
+		"buffer": func() *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		}},
+	)
+
+	var app nestedApp
+	err := cont.Fill(&app)
+	require.NoError(t, err)
+	require.NotNil(t, app.HTTP.Router.Logger.Buffer)
+}
+
+func TestFill_NestedErrorPath(t *testing.T) {
+	cont := New()
+
+	var app nestedApp
+	err := cont.Fill(&app)
+	require.ErrorContains(t, err, "nestedApp.HTTP.Router.Logger.Buffer")
+}
+
+type recursiveDeps struct {
+	testutils.MyDeps
+	Nested *struct {
+		Service testutils.MyService
+	}
+	Service *testutils.MyService `wiring:",omit"`
+}
+
+func TestFillWith_Recursive(t *testing.T) {
+	cont := New()
+	cont.Transient(testutils.NewService)
+	cont.Token(map[string]any{
+		"buffer": func() *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		}},
+	)
+
+	var deps recursiveDeps
+	err := cont.FillWith(&deps, FillOptions{Recursive: true})
+	require.NoError(t, err)
+	require.NoError(t, deps.CheckResolvedDependencies(), "embedded structs should be filled")
+	require.NotNil(t, deps.Nested, "nil struct pointers should be allocated")
+
+	err = cont.Fill(&recursiveDeps{})
+	require.Error(t, err, "nested structs are resolved when fill is not recursive")
+}
+
+type selfReferencingNode struct {
+	Next *selfReferencingNode `wiring:",fill"`
+}
+
+func TestFill_SelfReferencingStruct(t *testing.T) {
+	cont := New()
+
+	var node selfReferencingNode
+	err := cont.Fill(&node)
+	var wiringErr *errors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, errors.E_CIRCULAR_DEPENDENCY, wiringErr.Code())
+}
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected wiringTag
+	}{
+		{
+			tag:      "",
+			expected: wiringTag{},
+		},
+		{
+			tag:      "buffer,omit",
+			expected: wiringTag{omit: true},
+		},
+		{
+			tag:      ",fill",
+			expected: wiringTag{fill: true},
+		},
+		{
+			tag:      "buffer, fill",
+			expected: wiringTag{token: "buffer", fill: true},
+		},
+	}
+
+	for _, ttest := range tests {
+		t.Run(ttest.tag, func(t *testing.T) {
+			assert.Equal(t, ttest.expected, parseTag(ttest.tag))
+		})
+	}
+}
+
+func TestFillWith_Strict(t *testing.T) {
+	cont := New()
+	cont.Transient(testutils.NewService)
+	cont.Token(map[string]any{
+		"buffer": func() *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		}},
+	)
+
+	var deps testutils.MyDeps
+	err := cont.FillWith(&deps, FillOptions{Mode: FillStrict})
+	require.ErrorContains(t, err, "MyDeps.autoIgnored", "unexported fields should fail on strict mode")
+
+	var tagged struct {
+		Service     testutils.MyService
+		autoIgnored *bytes.Buffer `wiring:",omit"`
+	}
+	err = cont.FillWith(&tagged, FillOptions{Mode: FillStrict})
+	require.NoError(t, err)
+}
+
+func TestFillWith_Lenient(t *testing.T) {
+	cont := New()
+	cont.Transient(testutils.NewService)
+
+	var deps testutils.MyDeps
+	err := cont.FillWith(&deps, FillOptions{Mode: FillLenient})
+	require.NoError(t, err)
+	require.Nil(t, deps.Buffer, "fields without resolver should be skipped")
+}
+
+func TestFillWith_CollectErrors(t *testing.T) {
+	cont := New()
+
+	var deps testutils.MyDeps
+	err := cont.FillWith(&deps, FillOptions{CollectErrors: true, Mode: FillStrict})
+
+	var wiringErr *errors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, errors.E_MULTIPLE_ERRORS, wiringErr.Code())
+	require.ErrorContains(t, err, "MyDeps.Service")
+	require.ErrorContains(t, err, "MyDeps.Buffer")
+	require.ErrorContains(t, err, "MyDeps.autoIgnored")
+
+	err = cont.Fill(&deps)
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, errors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code(), "fill should stop on the first error by default")
+}
+
+type privateDeps struct {
+	service testutils.MyService `wiring:",private"`
+	buffer  *bytes.Buffer       `wiring:"buffer,private"`
+}
+
+type embeddingPrivateDeps struct {
+	privateDeps `wiring:",fill,private"`
+	Nested      struct {
+		deps *privateDeps `wiring:",fill,private"`
+	} `wiring:",fill"`
+}
+
+func TestFill_Private(t *testing.T) {
+	cont := New()
+	cont.Transient(testutils.NewService)
+	cont.Token(map[string]any{
+		"buffer": func() *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		}},
+	)
+
+	var deps privateDeps
+	err := cont.FillWith(&deps, FillOptions{Mode: FillStrict})
+	require.NoError(t, err, "private fields should not fail on strict mode")
+	require.NotNil(t, deps.buffer)
+
+	var embedding embeddingPrivateDeps
+	err = cont.Fill(&embedding)
+	require.NoError(t, err)
+	require.NotNil(t, embedding.buffer, "embedded unexported structs should be filled")
+	require.NotNil(t, embedding.Nested.deps.buffer, "nested unexported structs should be filled")
+}
+
+func TestStruct_Private(t *testing.T) {
+	cont := New()
+	cont.Transient(testutils.NewService)
+	cont.Token(map[string]any{
+		"buffer": func() *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		}},
+	)
+	err := cont.Transient(Struct[*privateDeps]())
+	require.NoError(t, err)
+
+	deps, err := Resolve[*privateDeps](cont)
+	require.NoError(t, err)
+	require.NotNil(t, deps.buffer)
+}
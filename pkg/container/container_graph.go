@@ -0,0 +1,182 @@
+package container
+
+import (
+	"reflect"
+
+	"github.com/4strodev/wiring_graphs/pkg/graph"
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/set"
+)
+
+// This file exports the dependency graph of a container
+
+// scopedConfig is a resolver with the container that owns it. Dependencies
+// of a resolver are resolved from the container that owns it.
+type scopedConfig struct {
+	config *resolverConfig
+	owner  *Container
+	token  string
+}
+
+// lookup returns the resolver injected for t into the resolvers of module
+// and the container that owns it
+func (c *Container) lookup(module string, t reflect.Type) (*resolverConfig, *Container) {
+	private, ok := c.privateIndex[module][t]
+	if ok {
+		return private, c
+	}
+
+	for current := c; current != nil; current = current.parent {
+		config, ok := current.typeIndex[t]
+		if ok {
+			return config, current
+		}
+	}
+
+	return nil, nil
+}
+
+// lookupToken returns the resolver registered for token on this container
+// or its parents
+func (c *Container) lookupToken(token string) *resolverConfig {
+	for current := c; current != nil; current = current.parent {
+		config, ok := current.tokenIndex[token]
+		if ok {
+			return config
+		}
+	}
+
+	return nil
+}
+
+// dependencyConfigs returns the resolvers injected into config when it's
+// resolved from this container. Deferred dependencies and tokens resolved
+// by property sources have no resolver.
+func (c *Container) dependencyConfigs(config *resolverConfig) []*resolverConfig {
+	dependencies := []*resolverConfig{}
+	for _, inputType := range config.node.Val.Input() {
+		dependency, _ := c.lookup(config.module, inputType)
+		if dependency != nil {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	for _, implicit := range config.dependencies {
+		_, isDeferred := asDeferred(implicit.t)
+		if isDeferred {
+			continue
+		}
+
+		var dependency *resolverConfig
+		if implicit.token != "" {
+			dependency = c.lookupToken(implicit.token)
+		} else {
+			dependency, _ = c.lookup(config.module, implicit.t)
+		}
+		if dependency != nil {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies
+}
+
+// privateKey identifies a private resolver
+type privateKey struct {
+	module string
+	t      reflect.Type
+}
+
+// visibleConfigs returns the resolvers that can be resolved from this
+// container, including the private ones of its modules. Resolvers of a
+// child hide the ones of its parents.
+func (c *Container) visibleConfigs() []scopedConfig {
+	configs := []scopedConfig{}
+	types := set.New[reflect.Type]()
+	tokens := set.New[string]()
+	privates := set.New[privateKey]()
+	for current := c; current != nil; current = current.parent {
+		for module, private := range current.privateIndex {
+			for t, config := range private {
+				key := privateKey{module: module, t: t}
+				if privates.Has(key) {
+					continue
+				}
+				privates.Add(key)
+				configs = append(configs, scopedConfig{config: config, owner: current})
+			}
+		}
+
+		for t, config := range current.typeIndex {
+			if types.Has(t) {
+				continue
+			}
+			types.Add(t)
+			configs = append(configs, scopedConfig{config: config, owner: current})
+		}
+
+		for token, config := range current.tokenIndex {
+			if tokens.Has(token) {
+				continue
+			}
+			tokens.Add(token)
+			configs = append(configs, scopedConfig{config: config, owner: current, token: token})
+		}
+	}
+
+	return configs
+}
+
+// Graph returns a read only view of the dependencies that can be resolved
+// from this container, including the ones registered on its parents
+func (c *Container) Graph() (*graph.Graph, error) {
+	g, _, err := c.buildGraph()
+	return g, err
+}
+
+// buildGraph returns the graph of the container and the node of each
+// visible resolver
+func (c *Container) buildGraph() (*graph.Graph, map[*resolverConfig]*graph.Node, error) {
+	err := c.ensureNodesConnected()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	builder := graph.Builder{}
+	configs := c.visibleConfigs()
+	nodes := map[*resolverConfig]*graph.Node{}
+	for _, scoped := range configs {
+		lifetime := graph.Transient
+		if scoped.config.singleton {
+			lifetime = graph.Singleton
+		}
+
+		node := builder.Add(scoped.config.providedType(), scoped.token, lifetime)
+		node.Module = scoped.config.module
+		node.Private = scoped.config.private
+		nodes[scoped.config] = node
+	}
+
+	for _, scoped := range configs {
+		for _, dependency := range scoped.owner.dependencyConfigs(scoped.config) {
+			// resolvers of a parent can depend on resolvers hidden by
+			// this container, like the one injecting the parent itself
+			dependencyNode, ok := nodes[dependency]
+			if !ok {
+				continue
+			}
+
+			builder.Connect(dependencyNode, nodes[scoped.config])
+		}
+	}
+
+	return builder.Build(), nodes, nil
+}
+
+// providedType returns the type of the values returned by the resolver
+func (r *resolverConfig) providedType() reflect.Type {
+	if r.declaredType != nil {
+		return r.declaredType
+	}
+
+	return r.node.Val.Type()
+}
@@ -6,9 +6,13 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/4strodev/wiring_graphs/pkg/container"
+	"github.com/4strodev/wiring_graphs/pkg/containertest"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/graph"
 	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
 	"github.com/stretchr/testify/require"
 )
@@ -25,8 +29,7 @@ func TestDetectCircularDependencies(t *testing.T) {
 	})
 	require.NoError(t, err, "container should allow add dependencies without errors")
 
-	_, err = cont.DetectCircularDependencies()
-	require.NoError(t, err, "circular dependencies should not be detected")
+	containertest.AssertNoCycles(t, cont)
 }
 
 func TestDetectCircularDependencies_SelfReference(t *testing.T) {
@@ -45,8 +48,7 @@ func TestResolve(t *testing.T) {
 
 	cont.Transient(testutils.NewService)
 
-	_, err := container.Resolve[testutils.MyService](cont)
-	require.NoError(t, err, "testutils.MyService should be resolved")
+	containertest.AssertResolvable[testutils.MyService](t, cont)
 }
 
 func TestResolve_WithDependencies(t *testing.T) {
@@ -59,11 +61,8 @@ func TestResolve_WithDependencies(t *testing.T) {
 	err := cont.Transient(dependantResolver, testutils.NewService)
 	require.NoError(t, err)
 
-	_, err = container.Resolve[testutils.MyService](cont)
-	require.NoError(t, err, "testutils.MyService should be resolved")
-
-	buf, err := container.Resolve[*bytes.Buffer](cont)
-	require.NoError(t, err)
+	containertest.AssertResolvable[testutils.MyService](t, cont)
+	buf := containertest.AssertResolvable[*bytes.Buffer](t, cont)
 	require.NotNil(t, buf)
 }
 
@@ -145,8 +144,235 @@ func TestDerived(t *testing.T) {
 		return bytes.NewBuffer([]byte{})
 	})
 
-	derived := cont.Derived()
-	buffer, err := container.Resolve[*bytes.Buffer](derived)
+	derived := containertest.New(t, cont)
+	buffer := containertest.AssertResolvable[*bytes.Buffer](t, derived)
+	require.NotNil(t, buffer)
+}
+
+func TestResolveKey(t *testing.T) {
+	cont := container.New()
+	writerKey := container.NewKey[io.Writer]("writer")
+
+	err := container.RegisterKey(cont, writerKey, func() *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
 	require.NoError(t, err)
+
+	writer, err := container.ResolveKey(cont, writerKey)
+	require.NoError(t, err)
+	require.IsType(t, &bytes.Buffer{}, writer)
+}
+
+func TestRegisterKey_TypeMismatch(t *testing.T) {
+	cont := container.New()
+	bufferKey := container.NewKey[*bytes.Buffer]("buffer")
+
+	err := container.RegisterKey(cont, bufferKey, testutils.NewService)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_TYPE_ERROR, wiringErr.Code())
+}
+
+func TestFill_KeyTag(t *testing.T) {
+	cont := container.New()
+	bufferKey := container.NewKey[*bytes.Buffer]("buffer")
+
+	cont.Transient(testutils.NewService)
+	err := container.RegisterKeySingleton(cont, bufferKey, func() *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+	require.NoError(t, err)
+
+	var deps testutils.MyDeps
+	err = cont.Fill(&deps)
+	require.NoError(t, err)
+	require.NoError(t, deps.CheckResolvedDependencies())
+
+	buffer, err := container.ResolveKey(cont, bufferKey)
+	require.NoError(t, err)
+	require.Same(t, buffer, deps.Buffer)
+}
+
+func TestFill_TokenTypeMismatch(t *testing.T) {
+	cont := container.New()
+
+	cont.Transient(testutils.NewService)
+	cont.Token(map[string]any{"buffer": testutils.NewService})
+
+	var deps testutils.MyDeps
+	err := cont.Fill(&deps)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_TYPE_ERROR, wiringErr.Code())
+}
+
+func TestResolve_RegisterAfterResolve(t *testing.T) {
+	cont := container.New()
+
+	cont.Transient(testutils.NewService)
+	_, err := container.Resolve[testutils.MyService](cont)
+	require.NoError(t, err)
+
+	err = cont.Transient(func(s testutils.MyService) *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+	require.NoError(t, err)
+
+	_, err = container.Resolve[testutils.MyService](cont)
+	require.NoError(t, err, "already connected nodes should not be reported as cycles")
+	_, err = container.Resolve[*bytes.Buffer](cont)
+	require.NoError(t, err)
+}
+
+func TestResolve_CycleAddedLater(t *testing.T) {
+	cont := container.New()
+
+	cont.Transient(func(w io.Writer) *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+	_, err := container.Resolve[*bytes.Buffer](cont)
+	require.Error(t, err, "io.Writer is not registered")
+
+	cont.Transient(func(b *bytes.Buffer) io.Writer {
+		return b
+	})
+	_, err = container.Resolve[*bytes.Buffer](cont)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_CIRCULAR_DEPENDENCY, wiringErr.Code())
+}
+
+func TestDerived_DependsOnParent(t *testing.T) {
+	cont := container.New()
+	cont.Singleton(testutils.NewService)
+
+	derived := containertest.New(t, cont)
+	err := derived.Transient(func(s testutils.MyService) *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+	require.NoError(t, err)
+
+	buffer := containertest.AssertResolvable[*bytes.Buffer](t, derived)
 	require.NotNil(t, buffer)
 }
+
+func TestDerived_ValidatesParent(t *testing.T) {
+	cont := container.New()
+	cont.Singleton(func(w io.Writer) *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	}, func(b *bytes.Buffer) io.Writer {
+		return b
+	})
+
+	_, err := container.Resolve[io.Writer](cont.Derived())
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr, "parents should be connected before resolving from a derived container")
+	require.Equal(t, wiringerrors.E_CIRCULAR_DEPENDENCY, wiringErr.Code())
+}
+
+func TestDerived_ShadowsParent(t *testing.T) {
+	cont := container.New()
+	cont.Transient(func() io.Writer {
+		return os.Stdout
+	})
+
+	derived := containertest.New(t, cont)
+	derived.Singleton(func(w io.Writer) *writerHolder {
+		return &writerHolder{writer: w}
+	})
+	holder := containertest.AssertResolvable[*writerHolder](t, derived)
+	require.Same(t, os.Stdout, holder.writer)
+
+	buffer := bytes.NewBuffer([]byte{})
+	derived.Transient(func() io.Writer {
+		return buffer
+	})
+	holder = containertest.AssertResolvable[*writerHolder](t, derived)
+	require.Same(t, buffer, holder.writer, "dependents should use the resolver that hides the parent one")
+
+	derived = containertest.New(t, cont)
+	derived.Transient(func(w io.Writer) *writerHolder {
+		return &writerHolder{writer: w}
+	})
+	containertest.AssertResolvable[*writerHolder](t, derived)
+
+	derived.Transient(func(h *writerHolder) io.Writer {
+		return bytes.NewBuffer([]byte{})
+	})
+	_, err := container.Resolve[*writerHolder](derived)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_CIRCULAR_DEPENDENCY, wiringErr.Code())
+}
+
+func TestGraph(t *testing.T) {
+	cont := container.New()
+	cont.Singleton(testutils.NewService)
+
+	derived := cont.Derived()
+	derived.Transient(func(s testutils.MyService) io.Writer {
+		return os.Stdout
+	})
+	derived.Token(map[string]any{
+		"buffer": func(w io.Writer) *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		}},
+	)
+
+	g, err := derived.Graph()
+	require.NoError(t, err)
+
+	service, ok := g.Lookup(reflect.TypeFor[testutils.MyService]())
+	require.True(t, ok, "nodes of the parent should be included")
+	require.Equal(t, graph.Singleton, service.Lifetime)
+
+	writer, ok := g.Lookup(reflect.TypeFor[io.Writer]())
+	require.True(t, ok)
+	require.Equal(t, []*graph.Node{service}, writer.Dependencies())
+
+	buffer, ok := g.LookupToken("buffer")
+	require.True(t, ok)
+	require.Equal(t, []*graph.Node{buffer}, writer.Dependents())
+
+	// *container.Container is registered on both containers
+	require.Len(t, g.Nodes(), 4)
+}
+
+func TestGraph_ParentInjectsContainer(t *testing.T) {
+	cont := container.New()
+	cont.Transient(func(c *container.Container) testutils.MyService {
+		return testutils.MyService{}
+	})
+
+	g, err := cont.Derived().Graph()
+	require.NoError(t, err)
+
+	service, ok := g.Lookup(reflect.TypeFor[testutils.MyService]())
+	require.True(t, ok)
+	require.Empty(t, service.Dependencies(), "the container injected into the parent is hidden by the derived one")
+}
+
+func TestResolve_ConcreteTypeOfInterface(t *testing.T) {
+	cont := container.New()
+	cont.Transient(func() io.Writer {
+		return bytes.NewBufferString("buffer")
+	})
+	cont.Token(map[string]any{
+		"writer": func() io.Writer {
+			return bytes.NewBufferString("token")
+		},
+	})
+
+	writer, err := container.Resolve[io.Writer](cont)
+	require.NoError(t, err)
+	require.IsType(t, &bytes.Buffer{}, writer)
+
+	buffer, err := container.ResolveToken[*bytes.Buffer](cont, "writer")
+	require.NoError(t, err)
+	require.Equal(t, "token", buffer.String())
+
+	_, err = container.ResolveToken[*os.File](cont, "writer")
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_TYPE_ERROR, wiringErr.Code())
+}
@@ -0,0 +1,64 @@
+package container
+
+import (
+	"reflect"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/graph"
+)
+
+// This file contains queries that explain why a dependency is needed
+
+// nodeFor returns the graph of the container and the node registered for t
+func (c *Container) nodeFor(t reflect.Type) (*graph.Graph, *graph.Node, error) {
+	g, err := c.Graph()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	node, ok := g.Lookup(t)
+	if !ok {
+		return nil, nil, errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency not found for type %v", t)
+	}
+
+	return g, node, nil
+}
+
+// Dependents returns every resolver that requires T, directly or through
+// other resolvers
+func Dependents[T any](c *Container) ([]*graph.Node, error) {
+	g, node, err := c.nodeFor(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	return g.TransitiveDependents(node), nil
+}
+
+// Dependencies returns every resolver required to build T, directly or
+// through other resolvers
+func Dependencies[T any](c *Container) ([]*graph.Node, error) {
+	g, node, err := c.nodeFor(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	return g.TransitiveDependencies(node), nil
+}
+
+// PathBetween returns the shortest chain of resolvers that makes A depend
+// on B, starting with A and ending with B. It returns nil when A does not
+// depend on B.
+func PathBetween[A, B any](c *Container) ([]*graph.Node, error) {
+	g, from, err := c.nodeFor(reflect.TypeFor[A]())
+	if err != nil {
+		return nil, err
+	}
+
+	to, ok := g.Lookup(reflect.TypeFor[B]())
+	if !ok {
+		return nil, errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency not found for type %v", reflect.TypeFor[B]())
+	}
+
+	return g.PathBetween(from, to), nil
+}
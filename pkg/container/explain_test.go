@@ -0,0 +1,86 @@
+package container_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	"github.com/4strodev/wiring_graphs/pkg/graph"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+// newExplainContainer registers
+//
+//	*bytes.Buffer -> *slog.Logger -> testutils.MyService
+//	io.Writer -> *slog.Logger
+func newExplainContainer() *container.Container {
+	cont := container.New()
+	cont.Transient(func() io.Writer {
+		return os.Stdout
+	}, func(w io.Writer, buffer *bytes.Buffer) *slog.Logger {
+		return slog.New(slog.NewTextHandler(w, nil))
+	}, func(logger *slog.Logger) testutils.MyService {
+		return testutils.MyService{}
+	}, func() *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+
+	return cont
+}
+
+func nodeNames(nodes []*graph.Node) []string {
+	names := []string{}
+	for _, node := range nodes {
+		names = append(names, node.String())
+	}
+
+	return names
+}
+
+func TestDependents(t *testing.T) {
+	cont := newExplainContainer()
+
+	dependents, err := container.Dependents[io.Writer](cont)
+	require.NoError(t, err)
+	require.Equal(t, []string{"*slog.Logger", "testutils.MyService"}, nodeNames(dependents))
+}
+
+func TestDependencies(t *testing.T) {
+	cont := newExplainContainer()
+
+	dependencies, err := container.Dependencies[testutils.MyService](cont)
+	require.NoError(t, err)
+	require.Equal(t, []string{"*bytes.Buffer", "*slog.Logger", "io.Writer"}, nodeNames(dependencies))
+
+	_, err = container.Dependencies[int](cont)
+	require.Error(t, err)
+}
+
+func TestPathBetween(t *testing.T) {
+	cont := newExplainContainer()
+
+	path, err := container.PathBetween[testutils.MyService, *bytes.Buffer](cont)
+	require.NoError(t, err)
+	require.Equal(t, []string{"testutils.MyService", "*slog.Logger", "*bytes.Buffer"}, nodeNames(path))
+
+	path, err = container.PathBetween[*bytes.Buffer, testutils.MyService](cont)
+	require.NoError(t, err)
+	require.Nil(t, path)
+}
+
+func TestWriteDependencies(t *testing.T) {
+	cont := newExplainContainer()
+	g, err := cont.Graph()
+	require.NoError(t, err)
+
+	service, _ := g.Lookup(reflect.TypeFor[testutils.MyService]())
+	var out bytes.Buffer
+	err = graph.WriteDependencies(&out, service)
+	require.NoError(t, err)
+	require.Equal(t, "testutils.MyService\n  *slog.Logger\n    *bytes.Buffer\n    io.Writer\n", out.String())
+}
@@ -0,0 +1,93 @@
+package container
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+)
+
+// This file contains the logic executed after the fields of a struct are
+// injected. Structs can finish their setup through setter methods and
+// the [PostConstruct] interface.
+
+// PostConstruct is implemented by structs that need to finish their setup
+// once their dependencies are injected. AfterInject is called by
+// [Container.Fill] and by the resolvers built with [Struct] after every
+// field and setter is injected.
+type PostConstruct interface {
+	AfterInject() error
+}
+
+// setterMethods returns the methods of pointerType that look like setters.
+// A setter is named SetX, receives a single parameter and returns nothing or
+// an error. Methods promoted from embedded structs are included, following
+// the method set of pointerType.
+func setterMethods(pointerType reflect.Type) []reflect.Method {
+	setters := []reflect.Method{}
+	for i := 0; i < pointerType.NumMethod(); i++ {
+		method := pointerType.Method(i)
+		if !strings.HasPrefix(method.Name, "Set") || len(method.Name) == len("Set") {
+			continue
+		}
+
+		// receiver is the first parameter
+		if method.Type.NumIn() != 2 {
+			continue
+		}
+
+		returnsError := method.Type.NumOut() == 1 && method.Type.Out(0) == reflect.TypeFor[error]()
+		if method.Type.NumOut() != 0 && !returnsError {
+			continue
+		}
+
+		setters = append(setters, method)
+	}
+
+	return setters
+}
+
+// afterFill calls the setters and the [PostConstruct] hook of the struct
+// reached by path. Setters whose parameter has no resolver are skipped.
+func (f *filler) afterFill(structValue reflect.Value, path string) error {
+	if !structValue.CanAddr() {
+		return nil
+	}
+	pointer := structValue.Addr()
+
+	for _, setter := range setterMethods(pointer.Type()) {
+		paramType := setter.Type.In(1)
+		if !f.container.canResolve(paramType) {
+			continue
+		}
+
+		arg, err := f.container.resolve(paramType, nil)
+		if err != nil {
+			err = f.fail(errors.Errorf(errors.E_POST_CONSTRUCT, "cannot resolve dependency for setter %s.%s: %w", path, setter.Name, err))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		out := pointer.Method(setter.Index).Call([]reflect.Value{arg})
+		if len(out) == 1 && !out[0].IsNil() {
+			err = f.fail(errors.Errorf(errors.E_POST_CONSTRUCT, "setter %s.%s failed: %w", path, setter.Name, out[0].Interface().(error)))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	hook, ok := pointer.Interface().(PostConstruct)
+	if !ok {
+		return nil
+	}
+
+	err := hook.AfterInject()
+	if err != nil {
+		return f.fail(errors.Errorf(errors.E_POST_CONSTRUCT, "post construct of %s failed: %w", path, err))
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package container_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+type hookedService struct {
+	Service testutils.MyService
+	buffer  *bytes.Buffer
+	calls   []string
+	fail    bool
+}
+
+func (s *hookedService) SetBuffer(buffer *bytes.Buffer) {
+	s.calls = append(s.calls, "SetBuffer")
+	s.buffer = buffer
+}
+
+// SetName has no resolver so it should be skipped
+func (s *hookedService) SetName(name string) error {
+	s.calls = append(s.calls, "SetName")
+	return nil
+}
+
+func (s *hookedService) AfterInject() error {
+	s.calls = append(s.calls, "AfterInject")
+	if s.fail {
+		return errors.New("artificial error")
+	}
+	return nil
+}
+
+func newHookedContainer() *container.Container {
+	cont := container.New()
+	cont.Transient(testutils.NewService, func() *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+
+	return cont
+}
+
+func TestFill_Hooks(t *testing.T) {
+	cont := newHookedContainer()
+
+	var service hookedService
+	err := cont.Fill(&service)
+	require.NoError(t, err)
+	require.NotNil(t, service.buffer)
+	require.Equal(t, []string{"SetBuffer", "AfterInject"}, service.calls)
+}
+
+func TestFill_HookError(t *testing.T) {
+	cont := newHookedContainer()
+
+	service := hookedService{fail: true}
+	err := cont.Fill(&service)
+
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_POST_CONSTRUCT, wiringErr.Code())
+	require.ErrorContains(t, err, "hookedService")
+}
+
+type embeddingHookedService struct {
+	hookedService `wiring:",fill,private"`
+}
+
+func TestFill_PromotedHooks(t *testing.T) {
+	cont := newHookedContainer()
+
+	var service embeddingHookedService
+	err := cont.Fill(&service)
+	require.NoError(t, err)
+	require.Equal(t, []string{"SetBuffer", "AfterInject"}, service.calls, "promoted hooks should be called once")
+}
+
+type overridingHookedService struct {
+	hookedService `wiring:",fill,private"`
+	outerCalls    []string
+}
+
+func (s *overridingHookedService) SetBuffer(buffer *bytes.Buffer) {
+	s.outerCalls = append(s.outerCalls, "SetBuffer")
+}
+
+func (s *overridingHookedService) AfterInject() error {
+	s.outerCalls = append(s.outerCalls, "AfterInject")
+	return nil
+}
+
+func TestFill_OverriddenHooks(t *testing.T) {
+	cont := newHookedContainer()
+
+	var service overridingHookedService
+	err := cont.Fill(&service)
+	require.NoError(t, err)
+	require.Equal(t, []string{"SetBuffer", "AfterInject"}, service.outerCalls, "methods of the outer struct should be called")
+	require.Empty(t, service.calls, "overridden methods of the embedded struct should not be called")
+
+	cont.Transient(container.Struct[*overridingHookedService]())
+	resolved, err := container.Resolve[*overridingHookedService](cont)
+	require.NoError(t, err)
+	require.Equal(t, []string{"SetBuffer", "AfterInject"}, resolved.outerCalls)
+}
+
+func TestStruct_Hooks(t *testing.T) {
+	cont := newHookedContainer()
+	cont.Transient(container.Struct[*hookedService]())
+
+	service, err := container.Resolve[*hookedService](cont)
+	require.NoError(t, err)
+	require.NotNil(t, service.buffer)
+	require.Equal(t, []string{"SetBuffer", "AfterInject"}, service.calls)
+}
+
+func TestStruct_SetterCycle(t *testing.T) {
+	cont := container.New()
+	cont.Transient(testutils.NewService, container.Struct[*hookedService]())
+
+	_, err := container.Resolve[*hookedService](cont)
+	require.NoError(t, err, "setters without resolver should not be required")
+
+	cont.Transient(func(*hookedService) *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+	_, err = container.Resolve[*hookedService](cont)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_CIRCULAR_DEPENDENCY, wiringErr.Code(), "setter parameters should be edges")
+}
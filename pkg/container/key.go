@@ -0,0 +1,58 @@
+package container
+
+import (
+	"reflect"
+)
+
+// Key is a typed token. It identifies a dependency by name like a plain
+// string token but also carries the type of the dependency, so registering
+// a resolver that returns an incompatible type fails early and resolving
+// it does not need an explicit type parameter.
+//
+//	var PrimaryDB = container.NewKey[*sql.DB]("primaryDB")
+//
+// Keys share the token namespace, so a struct field tagged with
+// `wiring:"primaryDB"` is filled with the dependency registered for PrimaryDB.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey returns a key identified by name for dependencies of type T
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{
+		name: name,
+	}
+}
+
+// Name returns the token used to register the dependency
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Type returns the type of the dependency identified by this key
+func (k Key[T]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (k Key[T]) String() string {
+	return k.name + "(" + k.Type().String() + ")"
+}
+
+// RegisterKey adds a transient resolver for the given key. The return type
+// of the resolver must be assignable to T.
+func RegisterKey[T any](c *Container, key Key[T], res any) error {
+	_, err := c.addToken(key.name, res, false, key.Type())
+	return err
+}
+
+// RegisterKeySingleton adds a singleton resolver for the given key. The return
+// type of the resolver must be assignable to T.
+func RegisterKeySingleton[T any](c *Container, key Key[T], res any) error {
+	_, err := c.addToken(key.name, res, true, key.Type())
+	return err
+}
+
+// keyName is implemented by every [Key] regardless of its type
+func (k Key[T]) keyName() string {
+	return k.name
+}
@@ -0,0 +1,150 @@
+package container
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+)
+
+// This file contains the logic of deferred dependencies. Resolvers and
+// filled structs can ask for a Lazy[T] or a Provider[T] instead of T to
+// postpone the construction of the dependency until it's needed.
+
+// deferredDependency is implemented by the types that the container knows
+// how to build without a registered resolver
+type deferredDependency interface {
+	// dependencyType returns the type that will be resolved on demand
+	dependencyType() reflect.Type
+	// bind returns a new instance that will use resolve to
+	// obtain the dependency
+	bind(resolve func() (reflect.Value, error)) any
+}
+
+// Lazy defers the resolution of T until the first call to [Lazy.Get].
+// The resolved value (or error) is kept so following calls return the same
+// result. Since the dependency is not built before the consumer a resolver
+// can receive a Lazy of a type that depends on itself.
+type Lazy[T any] struct {
+	state *lazyState[T]
+}
+
+type lazyState[T any] struct {
+	once    sync.Once
+	resolve func() (reflect.Value, error)
+	value   T
+	err     error
+}
+
+// Get resolves the dependency the first time is called and returns the
+// same value on any subsequent call
+func (l Lazy[T]) Get() (T, error) {
+	if l.state == nil {
+		var value T
+		return value, errNotBound[T]()
+	}
+
+	l.state.once.Do(func() {
+		l.state.value, l.state.err = convertResolved[T](l.state.resolve())
+	})
+
+	return l.state.value, l.state.err
+}
+
+func (l Lazy[T]) dependencyType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (l Lazy[T]) bind(resolve func() (reflect.Value, error)) any {
+	return Lazy[T]{
+		state: &lazyState[T]{
+			resolve: resolve,
+		},
+	}
+}
+
+// Provider resolves T on every call to [Provider.Get]. The lifetime of the
+// dependency is honored so transients return a new instance on each call
+// while singletons always return the same one.
+type Provider[T any] struct {
+	resolve func() (reflect.Value, error)
+}
+
+// Get resolves a new instance of the dependency
+func (p Provider[T]) Get() (T, error) {
+	if p.resolve == nil {
+		var value T
+		return value, errNotBound[T]()
+	}
+
+	return convertResolved[T](p.resolve())
+}
+
+func (p Provider[T]) dependencyType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (p Provider[T]) bind(resolve func() (reflect.Value, error)) any {
+	return Provider[T]{
+		resolve: resolve,
+	}
+}
+
+// asDeferred returns the deferred dependency for t when t is a
+// [Lazy] or a [Provider]
+func asDeferred(t reflect.Type) (deferredDependency, bool) {
+	if !t.Implements(reflect.TypeFor[deferredDependency]()) {
+		return nil, false
+	}
+
+	deferred, ok := reflect.Zero(t).Interface().(deferredDependency)
+	return deferred, ok
+}
+
+// resolveDeferred binds deferred to this container. When token is not
+// empty the dependency is resolved by token instead of by type. chain is
+// the resolution the dependency is injected into.
+func (c *Container) resolveDeferred(deferred deferredDependency, token string, chain *resolution) reflect.Value {
+	dependencyType := deferred.dependencyType()
+	bound := deferred.bind(func() (reflect.Value, error) {
+		err := c.ensureNodesConnected()
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		if token != "" {
+			return c.resolveTokenAs(token, dependencyType, chain)
+		}
+		return c.resolve(dependencyType, chain)
+	})
+
+	return reflect.ValueOf(bound)
+}
+
+func convertResolved[T any](value reflect.Value, err error) (T, error) {
+	var dependency T
+	if err != nil {
+		return dependency, err
+	}
+
+	target := reflect.ValueOf(&dependency).Elem()
+	// resolvers returning an interface can be resolved as the type of
+	// the value they hold
+	if value.Kind() == reflect.Interface && !value.IsNil() && !value.Type().AssignableTo(target.Type()) {
+		value = value.Elem()
+	}
+	if !value.Type().AssignableTo(target.Type()) {
+		return dependency, errCannotConvert[T]()
+	}
+
+	target.Set(value)
+	return dependency, nil
+}
+
+func errNotBound[T any]() error {
+	return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "deferred dependency %v was not created by a container", reflect.TypeFor[T]())
+}
+
+func errCannotConvert[T any]() error {
+	return errors.Errorf(errors.E_TYPE_ERROR, "cannot convert returned value into %s", reflect.TypeFor[T]().String())
+}
@@ -0,0 +1,148 @@
+package container_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+type lazyConsumer struct {
+	buffer container.Lazy[*bytes.Buffer]
+}
+
+func TestLazy(t *testing.T) {
+	cont := container.New()
+	calls := 0
+
+	cont.Transient(func() *bytes.Buffer {
+		calls++
+		return bytes.NewBuffer([]byte{})
+	}, func(buffer container.Lazy[*bytes.Buffer]) lazyConsumer {
+		return lazyConsumer{buffer: buffer}
+	})
+
+	consumer, err := container.Resolve[lazyConsumer](cont)
+	require.NoError(t, err)
+	require.Equal(t, 0, calls, "lazy dependency should not be built before Get")
+
+	buffer1, err := consumer.buffer.Get()
+	require.NoError(t, err)
+	buffer2, err := consumer.buffer.Get()
+	require.NoError(t, err)
+
+	require.Equal(t, 1, calls)
+	require.Same(t, buffer1, buffer2)
+}
+
+func TestProvider(t *testing.T) {
+	cont := container.New()
+
+	cont.Transient(func() *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+	cont.Singleton(testutils.NewService)
+
+	buffers, err := container.Resolve[container.Provider[*bytes.Buffer]](cont)
+	require.NoError(t, err)
+	buffer1, err := buffers.Get()
+	require.NoError(t, err)
+	buffer2, err := buffers.Get()
+	require.NoError(t, err)
+	require.NotSame(t, buffer1, buffer2, "transients should be created on each call")
+
+	services, err := container.Resolve[container.Provider[testutils.MyService]](cont)
+	require.NoError(t, err)
+	_, err = services.Get()
+	require.NoError(t, err)
+}
+
+type nodeA struct {
+	b container.Lazy[*nodeB]
+}
+
+type nodeB struct {
+	a *nodeA
+}
+
+func TestLazy_BreaksCycle(t *testing.T) {
+	cont := container.New()
+
+	cont.Singleton(func(b container.Lazy[*nodeB]) *nodeA {
+		return &nodeA{b: b}
+	}, func(a *nodeA) *nodeB {
+		return &nodeB{a: a}
+	})
+
+	a, err := container.Resolve[*nodeA](cont)
+	require.NoError(t, err)
+
+	b, err := a.b.Get()
+	require.NoError(t, err)
+	require.Same(t, a, b.a)
+}
+
+func TestLazy_GetWhileResolving(t *testing.T) {
+	cont := container.New()
+
+	cont.Singleton(func(b container.Lazy[*nodeB]) (*nodeA, error) {
+		_, err := b.Get()
+		return &nodeA{b: b}, err
+	}, func(a *nodeA) *nodeB {
+		return &nodeB{a: a}
+	})
+
+	_, err := container.Resolve[*nodeA](cont)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_CIRCULAR_DEPENDENCY, wiringErr.Code())
+
+	self := container.New()
+	self.Singleton(func(self container.Provider[*bytes.Buffer]) (*bytes.Buffer, error) {
+		_, err := self.Get()
+		return &bytes.Buffer{}, err
+	})
+
+	_, err = container.Resolve[*bytes.Buffer](self)
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_CIRCULAR_DEPENDENCY, wiringErr.Code())
+}
+
+func TestLazy_MissingDependency(t *testing.T) {
+	cont := container.New()
+
+	cont.Transient(func(buffer container.Lazy[*bytes.Buffer]) lazyConsumer {
+		return lazyConsumer{buffer: buffer}
+	})
+
+	_, err := container.Resolve[lazyConsumer](cont)
+	require.Error(t, err, "missing dependencies should be detected before Get")
+}
+
+func TestFill_Deferred(t *testing.T) {
+	cont := container.New()
+
+	cont.Token(map[string]any{
+		"buffer": func() *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		}},
+	)
+
+	var deps struct {
+		Buffer  container.Lazy[*bytes.Buffer]     `wiring:"buffer"`
+		Buffers container.Provider[*bytes.Buffer] `wiring:"buffer"`
+	}
+	err := cont.Fill(&deps)
+	require.NoError(t, err)
+
+	buffer, err := deps.Buffer.Get()
+	require.NoError(t, err)
+	require.NotNil(t, buffer)
+
+	buffer, err = deps.Buffers.Get()
+	require.NoError(t, err)
+	require.NotNil(t, buffer)
+}
@@ -0,0 +1,130 @@
+package container
+
+import (
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/graph"
+	"github.com/4strodev/wiring_graphs/pkg/resolver"
+)
+
+// This file contains the logic to build and dispose the singletons of a
+// container following the order of the graph
+
+// compareNodes sorts nodes by their type to get a stable order
+func compareNodes(a, b *graph.Node[resolver.DependencyResolver[any]]) int {
+	return strings.Compare(a.Val.Type().String(), b.Val.Type().String())
+}
+
+// sortedConfigs returns the resolvers registered on this container sorted
+// so every resolver goes after its dependencies
+func (c *Container) sortedConfigs() ([]*resolverConfig, error) {
+	err := c.ensureNodesConnected()
+	if err != nil {
+		return nil, err
+	}
+
+	configs := c.configsByNode()
+	nodes, ok := c.graph.TopologicalSort(compareNodes)
+	if !ok {
+		return nil, errors.Errorf(errors.E_CIRCULAR_DEPENDENCY, "cannot sort a graph with circular dependencies")
+	}
+
+	sorted := make([]*resolverConfig, 0, len(nodes))
+	for _, node := range nodes {
+		sorted = append(sorted, configs[node])
+	}
+
+	return sorted, nil
+}
+
+// Start resolves every singleton registered on this container following
+// the order of the graph, so a failing singleton is detected on startup
+// instead of the first time it's resolved
+func (c *Container) Start() error {
+	configs, err := c.sortedConfigs()
+	if err != nil {
+		return err
+	}
+
+	for _, config := range configs {
+		if !config.singleton {
+			continue
+		}
+
+		_, err = c.resolveConfig(config, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close closes the resolved singletons of this container that implement
+// [io.Closer] in the reverse order of the graph, so every singleton is
+// closed before its dependencies. When the graph cannot be connected
+// singletons are closed in the reverse order they were built and the
+// wiring error is returned with the rest. Singletons are released and
+// resolved again if requested after closing the container. Errors are
+// joined.
+func (c *Container) Close() error {
+	failures := []error{}
+	configs, err := c.sortedConfigs()
+	if err != nil {
+		failures = append(failures, err)
+		configs = c.builtSingletons()
+	}
+
+	c.builtMu.Lock()
+	c.built = nil
+	c.builtMu.Unlock()
+
+	for i := len(configs) - 1; i >= 0; i-- {
+		config := configs[i]
+		if !config.singleton {
+			continue
+		}
+
+		config.mu.Lock()
+		value, resolved := config.savedValue, config.resolved
+		config.resolved = false
+		config.savedValue = reflect.Value{}
+		config.mu.Unlock()
+		if !resolved {
+			continue
+		}
+
+		closer, ok := value.Interface().(io.Closer)
+		if !ok || (value.Kind() == reflect.Pointer && value.IsNil()) {
+			continue
+		}
+
+		err = closer.Close()
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+
+	return errors.Join(failures...)
+}
+
+// recordSingleton adds config to the singletons built by this container
+func (c *Container) recordSingleton(config *resolverConfig) {
+	c.builtMu.Lock()
+	defer c.builtMu.Unlock()
+
+	c.built = append(c.built, config)
+}
+
+// builtSingletons returns the singletons built by this container in the
+// order they were built
+func (c *Container) builtSingletons() []*resolverConfig {
+	c.builtMu.Lock()
+	defer c.builtMu.Unlock()
+
+	return slices.Clone(c.built)
+}
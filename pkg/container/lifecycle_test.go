@@ -0,0 +1,90 @@
+package container_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type closer struct {
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (c *closer) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+type database struct{ *closer }
+type repository struct{ *closer }
+
+func TestStartAndClose(t *testing.T) {
+	cont := container.New()
+	built := []string{}
+	closed := []string{}
+
+	cont.Singleton(func(db database) repository {
+		built = append(built, "repository")
+		return repository{&closer{name: "repository", closed: &closed}}
+	}, func() database {
+		built = append(built, "database")
+		return database{&closer{name: "database", closed: &closed}}
+	})
+
+	err := cont.Start()
+	require.NoError(t, err)
+	require.Equal(t, []string{"database", "repository"}, built)
+
+	err = cont.Close()
+	require.NoError(t, err)
+	require.Equal(t, []string{"repository", "database"}, closed, "dependents should be closed first")
+
+	_, err = container.Resolve[repository](cont)
+	require.NoError(t, err)
+	require.Len(t, built, 4, "singletons should be built again after closing")
+}
+
+func TestClose_JoinsErrors(t *testing.T) {
+	cont := container.New()
+	closed := []string{}
+
+	cont.Singleton(func() database {
+		return database{&closer{name: "database", closed: &closed, err: errors.New("database error")}}
+	}, func() repository {
+		return repository{&closer{name: "repository", closed: &closed, err: errors.New("repository error")}}
+	})
+
+	require.NoError(t, cont.Start())
+	err := cont.Close()
+	require.ErrorContains(t, err, "database error")
+	require.ErrorContains(t, err, "repository error")
+}
+
+type service struct{}
+
+func TestClose_InvalidGraph(t *testing.T) {
+	cont := container.New()
+	closed := []string{}
+
+	cont.Singleton(func(db database) repository {
+		return repository{&closer{name: "repository", closed: &closed}}
+	}, func() database {
+		return database{&closer{name: "database", closed: &closed}}
+	})
+	require.NoError(t, cont.Start())
+
+	cont.Transient(func(w io.Writer) service {
+		return service{}
+	})
+	err := cont.Close()
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr, "the wiring error should be reported")
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+	require.Equal(t, []string{"repository", "database"}, closed, "resolved singletons should be closed anyway")
+}
@@ -0,0 +1,263 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/convert"
+	"github.com/4strodev/wiring_graphs/pkg/resolver"
+	"gopkg.in/yaml.v3"
+)
+
+// This file contains the logic to register resolvers described by a
+// manifest, so implementations can be swapped without recompiling.
+//
+//	providers:
+//	  - factory: redisCache
+//	    lifetime: singleton
+//	    args: ["localhost:6379", 0]
+//	  - factory: smtpMailer
+//	    token: mailer
+
+var (
+	factoriesMutex sync.RWMutex
+	factories      = map[string]any{}
+)
+
+// RegisterFactory makes the resolver fn available to manifests as name.
+// It's usually called from an init function. Parameters of fn with basic
+// types (strings, numbers, booleans, durations and slices of them) are
+// taken from the args of the manifest, the rest are injected.
+func RegisterFactory(name string, fn any) error {
+	if !resolver.IsValid(fn) {
+		return errors.Errorf(errors.E_INVALID_RESOLVER, "Invalid resolver for factory '%s'", name)
+	}
+
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+
+	_, exists := factories[name]
+	if exists {
+		return errors.Errorf(errors.E_REDECLARED_DEPENDENCY, "factory already exists: %s", name)
+	}
+
+	factories[name] = fn
+	return nil
+}
+
+func lookupFactory(name string) (any, bool) {
+	factoriesMutex.RLock()
+	defer factoriesMutex.RUnlock()
+
+	fn, ok := factories[name]
+	return fn, ok
+}
+
+// Manifest describes the resolvers to register on a container
+type Manifest struct {
+	Providers []ManifestProvider `json:"providers" yaml:"providers"`
+}
+
+// ManifestProvider registers the resolver built by a factory
+type ManifestProvider struct {
+	// Factory is the name the factory was registered with
+	Factory string `json:"factory" yaml:"factory"`
+	// Token registers the resolver by token instead of by type
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+	// Lifetime is transient or singleton, transient when empty
+	Lifetime string `json:"lifetime,omitempty" yaml:"lifetime,omitempty"`
+	// Args are the values of the parameters of the factory with basic
+	// types, in order
+	Args []any `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// ReadManifest reads a JSON or YAML manifest. The format is chosen by the
+// extension of the file.
+func ReadManifest(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Errorf(errors.E_INVALID_CONFIG, "cannot read manifest: %w", err)
+	}
+
+	manifest := &Manifest{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, manifest)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, manifest)
+	default:
+		return nil, errors.Errorf(errors.E_INVALID_CONFIG, "unsupported manifest format %s", path)
+	}
+	if err != nil {
+		return nil, errors.Errorf(errors.E_INVALID_CONFIG, "cannot decode manifest %s: %w", path, err)
+	}
+
+	return manifest, nil
+}
+
+// LoadManifest reads the manifest at path and applies it
+func (c *Container) LoadManifest(path string) error {
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		return err
+	}
+
+	return c.Apply(manifest)
+}
+
+// Apply registers the providers of manifest. Every provider is checked
+// before registering any of them and the invalid ones are reported
+// together. Once registered the graph is validated, so missing and
+// circular dependencies are reported before any dependency is resolved.
+// When registering or validating fails the providers of manifest are
+// removed again.
+func (c *Container) Apply(manifest *Manifest) error {
+	registrations := make([]registration, 0, len(manifest.Providers))
+	failures := []error{}
+	for i, provider := range manifest.Providers {
+		r, err := provider.registration()
+		if err != nil {
+			failures = append(failures, errors.Errorf(errors.E_INVALID_CONFIG, "manifest provider %d (%s): %w", i, provider.Factory, err))
+			continue
+		}
+
+		registrations = append(registrations, r)
+	}
+
+	err := errors.Join(failures...)
+	if err != nil {
+		return err
+	}
+
+	configs := make([]*resolverConfig, 0, len(registrations))
+	for _, r := range registrations {
+		var config *resolverConfig
+		config, err = c.register("", r)
+		if err != nil {
+			break
+		}
+		configs = append(configs, config)
+	}
+
+	if err == nil {
+		err = c.ensureNodesConnected()
+	}
+	if err != nil {
+		for _, config := range configs {
+			c.unregister(config)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// registration returns the registration described by the provider
+func (p ManifestProvider) registration() (registration, error) {
+	fn, ok := lookupFactory(p.Factory)
+	if !ok {
+		return registration{}, fmt.Errorf("factory '%s' is not registered", p.Factory)
+	}
+
+	r := registration{token: p.Token}
+	switch p.Lifetime {
+	case "", "transient":
+	case "singleton":
+		r.singleton = true
+	default:
+		return r, fmt.Errorf("unknown lifetime '%s', expected transient or singleton", p.Lifetime)
+	}
+
+	res, err := bindArgs(fn, p.Args)
+	if err != nil {
+		return r, err
+	}
+	r.resolver = res
+
+	return r, nil
+}
+
+// bindArgs returns a resolver that calls fn with args as the values of its
+// parameters with basic types. The rest of parameters are parameters of the
+// returned resolver.
+func bindArgs(fn any, args []any) (any, error) {
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+
+	bound := map[int]reflect.Value{}
+	inputTypes := []reflect.Type{}
+	for i := range fnType.NumIn() {
+		inputType := fnType.In(i)
+		if !convert.Supported(inputType) {
+			inputTypes = append(inputTypes, inputType)
+			continue
+		}
+
+		if len(bound) == len(args) {
+			return nil, fmt.Errorf("factory %v expects more than %d args", fnType, len(args))
+		}
+
+		value, err := convert.FromString(argString(args[len(bound)]), inputType)
+		if err != nil {
+			return nil, fmt.Errorf("arg %d: %w", len(bound), err)
+		}
+		bound[i] = value
+	}
+
+	if len(bound) != len(args) {
+		return nil, fmt.Errorf("factory %v expects %d args, %d were given", fnType, len(bound), len(args))
+	}
+
+	if len(bound) == 0 {
+		return fn, nil
+	}
+
+	outputTypes := make([]reflect.Type, fnType.NumOut())
+	for i := range outputTypes {
+		outputTypes[i] = fnType.Out(i)
+	}
+
+	res := reflect.MakeFunc(reflect.FuncOf(inputTypes, outputTypes, false), func(in []reflect.Value) []reflect.Value {
+		callArgs := make([]reflect.Value, fnType.NumIn())
+		next := 0
+		for i := range callArgs {
+			value, ok := bound[i]
+			if !ok {
+				value = in[next]
+				next++
+			}
+			callArgs[i] = value
+		}
+
+		return fnValue.Call(callArgs)
+	})
+
+	return res.Interface(), nil
+}
+
+// argString returns the textual representation of a decoded arg. Lists are
+// joined with commas.
+func argString(arg any) string {
+	switch arg := arg.(type) {
+	case nil:
+		return ""
+	case float64:
+		// JSON numbers are decoded as floats, avoid the exponent notation
+		return strconv.FormatFloat(arg, 'f', -1, 64)
+	case []any:
+		values := make([]string, 0, len(arg))
+		for _, item := range arg {
+			values = append(values, argString(item))
+		}
+		return strings.Join(values, ",")
+	default:
+		return fmt.Sprint(arg)
+	}
+}
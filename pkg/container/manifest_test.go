@@ -0,0 +1,165 @@
+package container_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+type cache interface {
+	Name() string
+}
+
+type memoryCache struct {
+	size int
+}
+
+func (m *memoryCache) Name() string {
+	return fmt.Sprintf("memory(%d)", m.size)
+}
+
+type remoteCache struct {
+	addr    string
+	timeout time.Duration
+	service testutils.MyService
+}
+
+func (r *remoteCache) Name() string {
+	return fmt.Sprintf("remote(%s, %v)", r.addr, r.timeout)
+}
+
+func init() {
+	factories := map[string]any{
+		"memoryCache": func(size int) cache {
+			return &memoryCache{size: size}
+		},
+		"remoteCache": func(addr string, service testutils.MyService, timeout time.Duration) (cache, error) {
+			return &remoteCache{addr: addr, timeout: timeout, service: service}, nil
+		},
+		"stdout": func() io.Writer {
+			return os.Stdout
+		},
+		"service": testutils.NewService,
+	}
+
+	for name, factory := range factories {
+		err := container.RegisterFactory(name, factory)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func writeManifest(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadManifest(t *testing.T) {
+	manifests := map[string]string{
+		"wiring.yaml": `
+providers:
+  - factory: remoteCache
+    lifetime: singleton
+    args: ["localhost:6379", 1m30s]
+  - factory: service
+  - factory: memoryCache
+    token: local
+    args: [1000000]
+`,
+		"wiring.json": `{"providers": [
+	{"factory": "remoteCache", "lifetime": "singleton", "args": ["localhost:6379", "1m30s"]},
+	{"factory": "service"},
+	{"factory": "memoryCache", "token": "local", "args": [1000000]}
+]}`,
+	}
+
+	for name, content := range manifests {
+		t.Run(name, func(t *testing.T) {
+			cont := container.New()
+			err := cont.LoadManifest(writeManifest(t, name, content))
+			require.NoError(t, err)
+
+			remote, err := container.Resolve[cache](cont)
+			require.NoError(t, err)
+			require.Equal(t, "remote(localhost:6379, 1m30s)", remote.Name())
+
+			again, err := container.Resolve[cache](cont)
+			require.NoError(t, err)
+			require.Same(t, remote, again)
+
+			local, err := container.ResolveToken[cache](cont, "local")
+			require.NoError(t, err)
+			require.Equal(t, "memory(1000000)", local.Name())
+		})
+	}
+}
+
+func TestApply_InvalidProviders(t *testing.T) {
+	cont := container.New()
+	err := cont.Apply(&container.Manifest{
+		Providers: []container.ManifestProvider{
+			{Factory: "missing"},
+			{Factory: "memoryCache"},
+			{Factory: "memoryCache", Args: []any{"big"}},
+			{Factory: "stdout", Lifetime: "scoped"},
+		},
+	})
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_MULTIPLE_ERRORS, wiringErr.Code())
+	require.ErrorContains(t, err, "manifest provider 0 (missing): factory 'missing' is not registered")
+	require.ErrorContains(t, err, "manifest provider 1 (memoryCache): factory func(int) container_test.cache expects more than 0 args")
+	require.ErrorContains(t, err, `manifest provider 2 (memoryCache): arg 0: invalid value "big" for int`)
+	require.ErrorContains(t, err, "unknown lifetime 'scoped'")
+
+	_, err = container.Resolve[io.Writer](cont)
+	require.Error(t, err, "invalid manifests should not register any provider")
+}
+
+func TestApply_ValidatesGraph(t *testing.T) {
+	cont := container.New()
+	err := cont.Apply(&container.Manifest{
+		Providers: []container.ManifestProvider{
+			{Factory: "remoteCache", Args: []any{"localhost", "1s"}},
+		},
+	})
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+
+	g, err := cont.Graph()
+	require.NoError(t, err, "invalid providers should not stay registered")
+	_, found := g.Lookup(reflect.TypeFor[cache]())
+	require.False(t, found)
+
+	err = cont.Apply(&container.Manifest{
+		Providers: []container.ManifestProvider{
+			{Factory: "service"},
+			{Factory: "remoteCache", Args: []any{"localhost", "1s"}},
+		},
+	})
+	require.NoError(t, err)
+	remote, err := container.Resolve[cache](cont)
+	require.NoError(t, err)
+	require.Equal(t, "remote(localhost, 1s)", remote.Name())
+}
+
+func TestRegisterFactory_Duplicated(t *testing.T) {
+	err := container.RegisterFactory("service", testutils.NewService)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_REDECLARED_DEPENDENCY, wiringErr.Code())
+}
@@ -0,0 +1,287 @@
+package container
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+)
+
+// This file contains the logic to group registrations into modules that
+// can be shared between applications.
+
+// Module is a named group of registrations. Modules can include other
+// modules and are installed on a container with [Container.Install].
+//
+//	var Database = container.NewModule("database").
+//		Include(Logging).
+//		Singleton(NewDB, NewUserRepository)
+type Module struct {
+	name          string
+	registrations []registration
+	modules       []*Module
+}
+
+// registration is a resolver added to a module. Resolvers without token
+// are registered by type.
+type registration struct {
+	resolver  any
+	token     string
+	singleton bool
+	private   bool
+}
+
+// NewModule returns an empty module identified by name
+func NewModule(name string) *Module {
+	return &Module{
+		name: name,
+	}
+}
+
+// Name returns the name of the module
+func (m *Module) Name() string {
+	return m.name
+}
+
+// Transient adds transient resolvers to the module
+func (m *Module) Transient(resolvers ...any) *Module {
+	for _, res := range resolvers {
+		m.registrations = append(m.registrations, registration{resolver: res})
+	}
+
+	return m
+}
+
+// Singleton adds singleton resolvers to the module
+func (m *Module) Singleton(resolvers ...any) *Module {
+	for _, res := range resolvers {
+		m.registrations = append(m.registrations, registration{resolver: res, singleton: true})
+	}
+
+	return m
+}
+
+// Private adds transient resolvers that can only be injected into the
+// resolvers of this module. They can't be resolved from the container and
+// don't collide with the resolvers registered for the same type by other
+// modules or the container, which are hidden for this module.
+//
+// Private dependencies can't be injected lazily with [Lazy] or [Provider],
+// or resolved through the injected container.
+func (m *Module) Private(resolvers ...any) *Module {
+	for _, res := range resolvers {
+		m.registrations = append(m.registrations, registration{resolver: res, private: true})
+	}
+
+	return m
+}
+
+// PrivateSingleton adds singleton resolvers that can only be injected into
+// the resolvers of this module. See [Module.Private].
+func (m *Module) PrivateSingleton(resolvers ...any) *Module {
+	for _, res := range resolvers {
+		m.registrations = append(m.registrations, registration{resolver: res, singleton: true, private: true})
+	}
+
+	return m
+}
+
+// Token adds transient resolvers identified by token to the module
+func (m *Module) Token(dependencies map[string]any) *Module {
+	return m.addTokens(dependencies, false)
+}
+
+// TokenSingleton adds singleton resolvers identified by token to the module
+func (m *Module) TokenSingleton(dependencies map[string]any) *Module {
+	return m.addTokens(dependencies, true)
+}
+
+func (m *Module) addTokens(dependencies map[string]any, singleton bool) *Module {
+	// sorted so errors are reported in a stable order
+	for _, token := range slices.Sorted(maps.Keys(dependencies)) {
+		m.registrations = append(m.registrations, registration{
+			resolver:  dependencies[token],
+			token:     token,
+			singleton: singleton,
+		})
+	}
+
+	return m
+}
+
+// Include adds nested modules. They are installed before the
+// registrations of this module.
+func (m *Module) Include(modules ...*Module) *Module {
+	m.modules = append(m.modules, modules...)
+	return m
+}
+
+// Install registers the resolvers of the given modules and the modules
+// they include. A module included several times is installed once, but
+// installing two different modules with the same name fails with
+// [errors.E_DUPLICATE_MODULE].
+func (c *Container) Install(modules ...*Module) error {
+	for _, module := range modules {
+		err := c.install(module)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Container) install(module *Module) error {
+	installed, exists := c.modules[module.name]
+	if exists {
+		if installed == module {
+			return nil
+		}
+		return errors.Errorf(errors.E_DUPLICATE_MODULE, "a different module named '%s' is already installed", module.name)
+	}
+
+	// the module is kept while it's installed so modules that include
+	// each other are installed once, it's removed if the installation
+	// fails so it can be installed again
+	c.modules[module.name] = module
+	err := c.installRegistrations(module)
+	if err != nil {
+		delete(c.modules, module.name)
+		return err
+	}
+
+	return nil
+}
+
+// installRegistrations installs the modules included by module and
+// registers its resolvers. When a resolver cannot be registered the ones
+// registered before are removed.
+func (c *Container) installRegistrations(module *Module) error {
+	for _, nested := range module.modules {
+		err := c.install(nested)
+		if err != nil {
+			return err
+		}
+	}
+
+	conditionals := len(c.conditionals)
+	configs := []*resolverConfig{}
+	for _, registration := range module.registrations {
+		config, err := c.register(module.name, registration)
+		if err != nil {
+			for _, config := range configs {
+				c.unregister(config)
+			}
+			c.conditionals = c.conditionals[:conditionals]
+			return moduleError(module.name, err)
+		}
+
+		if config != nil {
+			configs = append(configs, config)
+		}
+	}
+
+	return nil
+}
+
+// register adds the resolver of r to the container as part of module.
+// Conditional resolvers are kept until the graph is connected, so it
+// returns a nil config for them.
+func (c *Container) register(module string, r registration) (*resolverConfig, error) {
+	cond, isConditional := r.resolver.(*conditional)
+	if isConditional {
+		c.conditionals = append(c.conditionals, pendingConditional{
+			module:       module,
+			registration: r,
+			conditions:   cond.conditions,
+		})
+		return nil, nil
+	}
+
+	var config *resolverConfig
+	var err error
+	switch {
+	case r.private:
+		config, err = c.addPrivate(module, r.resolver, r.singleton)
+	case r.token == "":
+		config, err = c.addType(r.resolver, r.singleton)
+	default:
+		config, err = c.addToken(r.token, r.resolver, r.singleton, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config.module = module
+	return config, nil
+}
+
+// addPrivate registers res under the type it returns as a private
+// resolver of module
+func (c *Container) addPrivate(module string, res any, singleton bool) (*resolverConfig, error) {
+	config, err := buildConfig(res)
+	if err != nil {
+		return nil, err
+	}
+
+	resType := config.node.Val.Type()
+	_, exists := c.privateIndex[module][resType]
+	if exists {
+		return nil, errors.Errorf(errors.E_REDECLARED_DEPENDENCY, "private dependency for this type already exists: %v", resType)
+	}
+
+	c.addNode(config)
+	config.singleton = singleton
+	config.private = true
+	config.module = module
+	c.indexPrivate(config, resType)
+
+	return config, nil
+}
+
+// unregister removes a resolver that is not connected yet
+func (c *Container) unregister(config *resolverConfig) {
+	switch {
+	case config.private:
+		delete(c.privateIndex[config.module], config.node.Val.Type())
+	case config.token != "":
+		delete(c.tokenIndex, config.token)
+	default:
+		delete(c.typeIndex, config.node.Val.Type())
+	}
+
+	c.pending = slices.DeleteFunc(c.pending, func(pending *resolverConfig) bool {
+		return pending == config
+	})
+	c.graph.Remove(config.node)
+}
+
+// indexPrivate adds config to the private resolvers of its module
+func (c *Container) indexPrivate(config *resolverConfig, t reflect.Type) {
+	private, ok := c.privateIndex[config.module]
+	if !ok {
+		private = make(map[reflect.Type]*resolverConfig)
+		c.privateIndex[config.module] = private
+	}
+
+	private[t] = config
+}
+
+// wrapError adds the module that registered the resolver to err
+func (r *resolverConfig) wrapError(err error) error {
+	if r.module == "" {
+		return err
+	}
+
+	return moduleError(r.module, err)
+}
+
+func moduleError(module string, err error) error {
+	wiringErr, ok := err.(*errors.WiringError)
+	if !ok {
+		return err
+	}
+
+	return errors.Errorf(wiringErr.Code(), "module '%s': %w", module, wiringErr)
+}
@@ -0,0 +1,183 @@
+package container_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstall(t *testing.T) {
+	logging := container.NewModule("logging").
+		Singleton(func() io.Writer {
+			return &bytes.Buffer{}
+		})
+	services := container.NewModule("services").
+		Include(logging).
+		Transient(testutils.NewService).
+		Token(map[string]any{
+			"buffer": func(w io.Writer) *bytes.Buffer {
+				return w.(*bytes.Buffer)
+			},
+		})
+
+	cont := container.New()
+	// logging is included twice
+	err := cont.Install(services, logging)
+	require.NoError(t, err)
+
+	writer, err := container.Resolve[io.Writer](cont)
+	require.NoError(t, err)
+	buffer, err := container.ResolveToken[*bytes.Buffer](cont, "buffer")
+	require.NoError(t, err)
+	require.Same(t, writer, buffer)
+
+	g, err := cont.Graph()
+	require.NoError(t, err)
+	node, ok := g.Lookup(reflect.TypeFor[io.Writer]())
+	require.True(t, ok)
+	require.Equal(t, "logging", node.Module)
+	require.Equal(t, "io.Writer [logging]", node.String())
+
+	node, ok = g.LookupToken("buffer")
+	require.True(t, ok)
+	require.Equal(t, "services", node.Module)
+}
+
+func TestInstall_DuplicateModule(t *testing.T) {
+	cont := container.New()
+	err := cont.Install(container.NewModule("services").Transient(testutils.NewService))
+	require.NoError(t, err)
+
+	err = cont.Install(container.NewModule("services"))
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DUPLICATE_MODULE, wiringErr.Code())
+}
+
+func TestInstall_ErrorsIncludeModule(t *testing.T) {
+	cont := container.New()
+	cont.Transient(testutils.NewService)
+
+	err := cont.Install(container.NewModule("services").Transient(testutils.NewService))
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_REDECLARED_DEPENDENCY, wiringErr.Code())
+	require.Contains(t, err.Error(), "module 'services'")
+
+	cont = container.New()
+	err = cont.Install(container.NewModule("buffers").Transient(func(w io.Writer) *bytes.Buffer {
+		return &bytes.Buffer{}
+	}))
+	require.NoError(t, err)
+
+	_, err = container.Resolve[*bytes.Buffer](cont)
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+	require.Contains(t, err.Error(), "module 'buffers'")
+}
+
+func TestInstall_Failed(t *testing.T) {
+	cont := container.New()
+	cont.Transient(testutils.NewService)
+
+	services := container.NewModule("services").
+		Singleton(func() io.Writer {
+			return &bytes.Buffer{}
+		}).
+		Transient(testutils.NewService)
+	err := cont.Install(services)
+	require.Error(t, err)
+
+	_, err = container.Resolve[io.Writer](cont)
+	require.Error(t, err, "registrations of a failed module should be removed")
+	err = cont.Install(services)
+	require.Error(t, err, "a failed module should not be reported as installed")
+}
+
+type sdkClient struct {
+	writer io.Writer
+}
+
+func TestInstall_Private(t *testing.T) {
+	public := &bytes.Buffer{}
+	private := &bytes.Buffer{}
+	sdk := container.NewModule("sdk").
+		PrivateSingleton(func() io.Writer {
+			return private
+		}).
+		Singleton(func(w io.Writer) *sdkClient {
+			return &sdkClient{writer: w}
+		})
+	other := container.NewModule("other").
+		Private(func() io.Writer {
+			return &bytes.Buffer{}
+		})
+
+	cont := container.New()
+	err := cont.Install(sdk, other)
+	require.NoError(t, err, "private resolvers should not collide")
+
+	_, err = container.Resolve[io.Writer](cont)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+	require.Contains(t, err.Error(), "private to module")
+
+	client, err := container.Resolve[*sdkClient](cont)
+	require.NoError(t, err)
+	require.Same(t, private, client.writer)
+
+	err = cont.Singleton(func() io.Writer {
+		return public
+	})
+	require.NoError(t, err)
+
+	writer, err := container.Resolve[io.Writer](cont)
+	require.NoError(t, err)
+	require.Same(t, public, writer)
+
+	g, err := cont.Graph()
+	require.NoError(t, err)
+	node, ok := g.Lookup(reflect.TypeFor[io.Writer]())
+	require.True(t, ok)
+	require.False(t, node.Private, "lookup should return the public node")
+
+	node, ok = g.Lookup(reflect.TypeFor[*sdkClient]())
+	require.True(t, ok)
+	require.Len(t, node.Dependencies(), 1)
+	require.True(t, node.Dependencies()[0].Private)
+	require.Equal(t, "sdk", node.Dependencies()[0].Module)
+}
+
+func TestInstall_PrivateNotVisibleToOtherModules(t *testing.T) {
+	cont := container.New()
+	err := cont.Install(
+		container.NewModule("sdk").Private(func() io.Writer {
+			return &bytes.Buffer{}
+		}),
+		container.NewModule("consumer").Transient(func(w io.Writer) *sdkClient {
+			return &sdkClient{writer: w}
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = container.Resolve[*sdkClient](cont)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+	require.Contains(t, err.Error(), "module 'consumer'")
+	require.Contains(t, err.Error(), "it's private to module 'sdk'")
+
+	err = cont.Install(container.NewModule("another-sdk").Private(func() io.Writer {
+		return &bytes.Buffer{}
+	}))
+	require.NoError(t, err)
+	_, err = container.Resolve[*sdkClient](cont)
+	require.ErrorContains(t, err, "it's private to modules 'another-sdk', 'sdk'", "every module should be listed in a stable order")
+}
@@ -40,4 +40,47 @@ func (c *MustContainer) Dependencies(resolvers ...any) *MustContainer  {
 	return c
 }
 
+func (c *MustContainer) Autowire(values ...any) *MustContainer {
+	err := c.container.Autowire(values...)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+func (c *MustContainer) Replace(resolvers ...any) *MustContainer {
+	err := c.container.Replace(resolvers...)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+func (c *MustContainer) ReplaceToken(dependencies map[string]any) *MustContainer {
+	err := c.container.ReplaceToken(dependencies)
+	if err != nil {
+		panic(err)
+	}
 
+	return c
+}
+
+func (c *MustContainer) Override(resolvers ...any) *MustContainer {
+	err := c.container.Override(resolvers...)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+func (c *MustContainer) Install(modules ...*Module) *MustContainer {
+	err := c.container.Install(modules...)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
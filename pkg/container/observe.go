@@ -0,0 +1,36 @@
+package container
+
+import "reflect"
+
+// ResolveEvent describes a dependency resolved by a container
+type ResolveEvent struct {
+	// Type is the type of the resolved value
+	Type reflect.Type
+	// Token is the token used to resolve the value, empty when it was
+	// resolved by type
+	Token string
+}
+
+// OnResolve registers observer to be notified each time this container
+// resolves a dependency, including cached singletons and dependencies of
+// other resolvers. Dependencies resolved by a parent container are notified
+// once, as the dependency requested to this container.
+//
+// Observers are called synchronously on the goroutine that resolves the
+// dependency.
+func (c *Container) OnResolve(observer func(ResolveEvent)) {
+	c.observers = append(c.observers, observer)
+}
+
+func (c *Container) notify(event ResolveEvent) {
+	for _, observer := range c.observers {
+		observer(event)
+	}
+}
+
+func (r *resolverConfig) event() ResolveEvent {
+	return ResolveEvent{
+		Type:  r.providedType(),
+		Token: r.token,
+	}
+}
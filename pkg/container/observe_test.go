@@ -0,0 +1,40 @@
+package container_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnResolve(t *testing.T) {
+	parent := container.New()
+	parent.Singleton(testutils.NewService)
+
+	cont := parent.Derived()
+	cont.Token(map[string]any{
+		"buffer": func(s testutils.MyService) *bytes.Buffer {
+			return &bytes.Buffer{}
+		},
+	})
+
+	events := []container.ResolveEvent{}
+	cont.OnResolve(func(event container.ResolveEvent) {
+		events = append(events, event)
+	})
+
+	_, err := container.ResolveToken[*bytes.Buffer](cont, "buffer")
+	require.NoError(t, err)
+	_, err = container.Resolve[testutils.MyService](cont)
+	require.NoError(t, err)
+
+	serviceType := reflect.TypeFor[testutils.MyService]()
+	require.Equal(t, []container.ResolveEvent{
+		{Type: serviceType},
+		{Type: reflect.TypeFor[*bytes.Buffer](), Token: "buffer"},
+		{Type: serviceType},
+	}, events)
+}
@@ -0,0 +1,102 @@
+package container
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/graph"
+)
+
+// This file contains the logic to override dependencies of parent
+// containers from a derived container.
+
+// Override registers the given resolvers on this container hiding the ones
+// registered for the same types, here or on a parent, keeping their
+// lifetime. Resolvers of parent containers that depend on an overridden
+// type, directly or through other resolvers, are registered again on this
+// container so they are built with the override when resolved from it.
+// Parent containers are not modified, which makes it suitable to swap
+// dependencies of a derived container used by a single test.
+func (c *Container) Override(resolvers ...any) error {
+	for _, res := range resolvers {
+		config, err := buildConfig(res)
+		if err != nil {
+			return err
+		}
+
+		resType := config.node.Val.Type()
+		old, exists := c.typeIndex[resType]
+		if exists {
+			c.replaceConfig(old, config)
+			c.typeIndex[resType] = config
+			continue
+		}
+
+		old, _ = c.lookup("", resType)
+		if old == nil {
+			return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "cannot override dependency for %v, it's not registered", resType)
+		}
+
+		err = c.shadowDependents(old)
+		if err != nil {
+			return err
+		}
+
+		config.singleton = old.singleton
+		config.module = old.module
+		c.addNode(config)
+		c.typeIndex[resType] = config
+	}
+
+	return c.ensureNodesConnected()
+}
+
+// shadowDependents registers on this container a copy of every resolver
+// owned by a parent that depends on config. Dependents owned by this
+// container are released and connected again.
+func (c *Container) shadowDependents(config *resolverConfig) error {
+	g, nodes, err := c.buildGraph()
+	if err != nil {
+		return err
+	}
+
+	scoped := map[*graph.Node]scopedConfig{}
+	for _, visible := range c.visibleConfigs() {
+		scoped[nodes[visible.config]] = visible
+	}
+
+	for _, node := range g.TransitiveDependents(nodes[config]) {
+		dependent := scoped[node]
+		if dependent.owner == c {
+			dependent.config.resolved = false
+			dependent.config.savedValue = reflect.Value{}
+			if !slices.Contains(c.pending, dependent.config) {
+				c.pending = append(c.pending, dependent.config)
+			}
+			continue
+		}
+
+		shadow, err := buildConfig(dependent.config.node.Val.Resolver)
+		if err != nil {
+			return err
+		}
+		shadow.singleton = dependent.config.singleton
+		shadow.declaredType = dependent.config.declaredType
+		shadow.token = dependent.token
+		shadow.module = dependent.config.module
+		shadow.private = dependent.config.private
+		shadow.dependencies = dependent.config.dependencies
+
+		c.addNode(shadow)
+		if dependent.token != "" {
+			c.tokenIndex[dependent.token] = shadow
+		} else if shadow.private {
+			c.indexPrivate(shadow, node.Type)
+		} else {
+			c.typeIndex[node.Type] = shadow
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package container_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverride(t *testing.T) {
+	parent := container.New()
+	production := bytes.NewBufferString("production")
+	fake := bytes.NewBufferString("fake")
+
+	parent.Singleton(func() io.Writer {
+		return production
+	}, func(w io.Writer) *writerHolder {
+		return &writerHolder{writer: w}
+	})
+
+	parentHolder, err := container.Resolve[*writerHolder](parent)
+	require.NoError(t, err)
+
+	child := parent.Derived()
+	err = child.Override(func() io.Writer {
+		return fake
+	})
+	require.NoError(t, err)
+
+	childHolder, err := container.Resolve[*writerHolder](child)
+	require.NoError(t, err)
+	require.Same(t, fake, childHolder.writer, "parent dependents should be built with the override")
+
+	again, err := container.Resolve[*writerHolder](child)
+	require.NoError(t, err)
+	require.Same(t, childHolder, again, "overridden dependents should keep their lifetime")
+
+	holder, err := container.Resolve[*writerHolder](parent)
+	require.NoError(t, err)
+	require.Same(t, parentHolder, holder, "parent should not be modified")
+	require.Same(t, production, holder.writer)
+}
+
+func TestOverride_LocalDependents(t *testing.T) {
+	parent := container.New()
+	fake := bytes.NewBufferString("fake")
+	parent.Singleton(func() io.Writer {
+		return bytes.NewBufferString("production")
+	})
+
+	child := parent.Derived()
+	child.Singleton(func(w io.Writer) *writerHolder {
+		return &writerHolder{writer: w}
+	})
+	_, err := container.Resolve[*writerHolder](child)
+	require.NoError(t, err)
+
+	err = child.Override(func() io.Writer {
+		return fake
+	})
+	require.NoError(t, err)
+
+	holder, err := container.Resolve[*writerHolder](child)
+	require.NoError(t, err)
+	require.Same(t, fake, holder.writer, "resolved singletons of the container should be released")
+}
+
+func TestOverride_NotRegistered(t *testing.T) {
+	cont := container.New().Derived()
+
+	err := cont.Override(testutils.NewService)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+}
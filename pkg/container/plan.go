@@ -0,0 +1,141 @@
+package container
+
+import (
+	"reflect"
+	"sync/atomic"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+)
+
+// resolutionPlan holds everything needed to call a resolver that can be
+// computed once the graph is connected, so resolving a dependency does
+// not need to inspect the resolver again
+type resolutionPlan struct {
+	function     reflect.Value
+	returnsError bool
+	inputs       []planInput
+}
+
+// planInput is a parameter of a resolver. When the parameter is resolved by
+// the same container config points to its resolver, on any other case
+// (parent containers and deferred dependencies) it's resolved by type.
+type planInput struct {
+	t      reflect.Type
+	config *resolverConfig
+}
+
+// resolution is a resolver being executed. Resolutions form a chain from
+// the resolver that was requested to the one being built, deferred
+// dependencies bound while building keep the chain so resolving them from
+// the resolver that requested them is detected as a cycle instead of
+// waiting for itself.
+type resolution struct {
+	config *resolverConfig
+	parent *resolution
+	// active until the resolver returns
+	active atomic.Bool
+}
+
+// building reports if config is being resolved by this chain
+func (r *resolution) building(config *resolverConfig) bool {
+	for current := r; current != nil; current = current.parent {
+		if current.config == config && current.active.Load() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Container) compilePlan(config *resolverConfig) *resolutionPlan {
+	function := reflect.ValueOf(config.node.Val.Resolver)
+	functionType := function.Type()
+
+	plan := &resolutionPlan{
+		function:     function,
+		returnsError: functionType.NumOut() == 2,
+		inputs:       make([]planInput, functionType.NumIn()),
+	}
+
+	for i := range plan.inputs {
+		inputType := functionType.In(i)
+		plan.inputs[i].t = inputType
+
+		_, isDeferred := asDeferred(inputType)
+		if !isDeferred {
+			plan.inputs[i].config = c.localConfig(config.module, inputType)
+		}
+	}
+
+	return plan
+}
+
+// resolveConfig executes the resolver of config, or returns the saved value
+// for resolved singletons. chain holds the resolvers being built that
+// requested config, nil when it's requested directly.
+func (c *Container) resolveConfig(config *resolverConfig, chain *resolution) (reflect.Value, error) {
+	if chain.building(config) {
+		return reflect.Value{}, errors.Errorf(errors.E_CIRCULAR_DEPENDENCY, "circular dependency found: %v is requested while it's being resolved", config.providedType())
+	}
+
+	current := &resolution{config: config, parent: chain}
+	current.active.Store(true)
+	defer current.active.Store(false)
+
+	if config.singleton {
+		// the lock is held while the singleton is built so it's built
+		// once, resolvers of its dependencies lock their own config
+		config.mu.Lock()
+		defer config.mu.Unlock()
+
+		if config.resolved {
+			c.notify(config.event())
+			return config.savedValue, nil
+		}
+	}
+
+	plan := config.plan
+	if plan == nil {
+		// the graph is not connected yet
+		plan = c.compilePlan(config)
+	}
+
+	args := make([]reflect.Value, len(plan.inputs))
+	for i, input := range plan.inputs {
+		var err error
+		if input.config != nil {
+			args[i], err = c.resolveConfig(input.config, current)
+		} else {
+			args[i], err = c.resolve(input.t, current)
+		}
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	out := plan.function.Call(args)
+	if plan.returnsError && !out[1].IsNil() {
+		err, ok := out[1].Interface().(error)
+		if !ok {
+			return reflect.Value{}, errors.Errorf(errors.E_INVALID_RESOLVER, "resolver should return an error not %s", out[1].Type().String())
+		}
+		return reflect.Value{}, err
+	}
+
+	resolvedValue := out[0]
+	if config.declaredType != nil {
+		resolvedValue = resolvedValue.Convert(config.declaredType)
+	}
+	if config.singleton {
+		config.resolved = true
+		config.savedValue = resolvedValue
+		c.recordSingleton(config)
+	} else {
+		config.mu.Lock()
+		config.resolved = true
+		config.mu.Unlock()
+	}
+
+	c.notify(config.event())
+	return resolvedValue, nil
+}
@@ -0,0 +1,183 @@
+package container
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/convert"
+	"gopkg.in/yaml.v3"
+)
+
+// This file contains the logic to resolve tokens from property sources.
+// Tokens without resolver are looked up as properties and converted to the
+// requested type, so settings can be injected without registering a
+// resolver for each one.
+//
+//	cont.AddPropertySource(container.EnvSource("APP_"))
+//	port, err := container.ResolveToken[int](cont, "http.port") // APP_HTTP_PORT
+
+// PropertySource provides textual values by key
+type PropertySource interface {
+	// Lookup returns the value of key and true when it exists
+	Lookup(key string) (string, bool)
+}
+
+// MapSource is a property source backed by a map
+type MapSource map[string]string
+
+func (m MapSource) Lookup(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// envSource reads properties from environment variables
+type envSource struct {
+	prefix string
+}
+
+// EnvSource returns a property source that reads environment variables.
+// Keys are upper cased, dots and dashes are replaced by underscores and the
+// result is prefixed by prefix, so "http.port" is read from HTTP_PORT.
+func EnvSource(prefix string) PropertySource {
+	return envSource{prefix: prefix}
+}
+
+func (e envSource) Lookup(key string) (string, bool) {
+	name := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+	return os.LookupEnv(e.prefix + name)
+}
+
+// FileSource returns a property source with the values of a JSON or YAML
+// file. Nested keys are joined with dots and lists are joined with commas.
+//
+//	http:
+//	  port: 8080         # http.port
+//	  origins: [a, b]    # http.origins
+func FileSource(path string) (PropertySource, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Errorf(errors.E_INVALID_CONFIG, "cannot read property file: %w", err)
+	}
+
+	var values map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		// keep numbers as written
+		decoder.UseNumber()
+		err = decoder.Decode(&values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &values)
+	default:
+		return nil, errors.Errorf(errors.E_INVALID_CONFIG, "unsupported property file format %s", path)
+	}
+	if err != nil {
+		return nil, errors.Errorf(errors.E_INVALID_CONFIG, "cannot decode property file %s: %w", path, err)
+	}
+
+	source := MapSource{}
+	flatten(source, "", values)
+	return source, nil
+}
+
+// flatten adds the values of a decoded file to source joining nested keys
+// with dots
+func flatten(source MapSource, prefix string, value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, nested := range value {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(source, key, nested)
+		}
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, fmt.Sprint(item))
+		}
+		source[prefix] = strings.Join(items, ",")
+	case nil:
+		source[prefix] = ""
+	default:
+		source[prefix] = fmt.Sprint(value)
+	}
+}
+
+// AddPropertySource adds sources used to resolve tokens without resolver.
+// Sources added later override the ones added before and derived
+// containers look up their parent sources after their own.
+func (c *Container) AddPropertySource(sources ...PropertySource) {
+	c.properties = append(c.properties, sources...)
+}
+
+// lookupProperty returns the value of key in the property sources of this
+// container and its parents
+func (c *Container) lookupProperty(key string) (string, bool) {
+	for current := c; current != nil; current = current.parent {
+		for _, source := range slices.Backward(current.properties) {
+			value, ok := source.Lookup(key)
+			if ok {
+				return value, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// hasToken reports if this container or any of its parents has a resolver
+// for token
+func (c *Container) hasToken(token string) bool {
+	for current := c; current != nil; current = current.parent {
+		_, ok := current.tokenIndex[token]
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canResolveToken reports if token has a resolver or a property that can
+// be converted to t
+func (c *Container) canResolveToken(token string, t reflect.Type) bool {
+	if c.hasToken(token) {
+		return true
+	}
+
+	_, ok := c.lookupProperty(token)
+	return ok && convert.Supported(t)
+}
+
+// resolveTokenAs resolves token. Tokens without resolver are looked up in
+// the property sources and converted to t.
+func (c *Container) resolveTokenAs(token string, t reflect.Type, chain *resolution) (reflect.Value, error) {
+	if c.hasToken(token) {
+		return c.resolveToken(token, chain)
+	}
+
+	value, ok := c.lookupProperty(token)
+	if !ok {
+		return c.resolveToken(token, chain)
+	}
+
+	if !convert.Supported(t) {
+		return reflect.Value{}, errors.Errorf(errors.E_TYPE_ERROR, "property '%s' cannot be converted to %v", token, t)
+	}
+
+	converted, err := convert.FromString(value, t)
+	if err != nil {
+		return reflect.Value{}, errors.Errorf(errors.E_TYPE_ERROR, "property '%s': %w", token, err)
+	}
+
+	c.notify(ResolveEvent{Type: t, Token: token})
+	return converted, nil
+}
@@ -0,0 +1,123 @@
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type httpSettings struct {
+	Port    int                 `wiring:"http.port"`
+	Timeout time.Duration       `wiring:"http.timeout"`
+	Debug   bool                `wiring:"debug"`
+	Origins []string            `wiring:"http.origins"`
+	Lazy    container.Lazy[int] `wiring:"http.port"`
+}
+
+func TestResolveToken_Properties(t *testing.T) {
+	cont := container.New()
+	cont.AddPropertySource(container.MapSource{
+		"http.port":    "8080",
+		"http.timeout": "5s",
+		"debug":        "true",
+		"http.origins": "a, b",
+	})
+
+	port, err := container.ResolveToken[int](cont, "http.port")
+	require.NoError(t, err)
+	require.Equal(t, 8080, port)
+
+	var settings httpSettings
+	err = cont.Fill(&settings)
+	require.NoError(t, err)
+	require.Equal(t, 8080, settings.Port)
+	require.Equal(t, 5*time.Second, settings.Timeout)
+	require.True(t, settings.Debug)
+	require.Equal(t, []string{"a", "b"}, settings.Origins)
+
+	lazyPort, err := settings.Lazy.Get()
+	require.NoError(t, err)
+	require.Equal(t, 8080, lazyPort)
+}
+
+func TestResolveToken_PropertyPrecedence(t *testing.T) {
+	t.Setenv("APP_HTTP_PORT", "9000")
+
+	parent := container.New()
+	parent.AddPropertySource(container.MapSource{"http.port": "8080", "http.host": "localhost"})
+	cont := parent.Derived()
+	cont.AddPropertySource(container.MapSource{"http.port": "8081"}, container.EnvSource("APP_"))
+
+	port, err := container.ResolveToken[int](cont, "http.port")
+	require.NoError(t, err)
+	require.Equal(t, 9000, port, "sources added later should have precedence")
+
+	host, err := container.ResolveToken[string](cont, "http.host")
+	require.NoError(t, err)
+	require.Equal(t, "localhost", host)
+
+	cont.Token(map[string]any{
+		"http.port": func() int {
+			return 1
+		},
+	})
+	port, err = container.ResolveToken[int](cont, "http.port")
+	require.NoError(t, err)
+	require.Equal(t, 1, port, "resolvers should have precedence over properties")
+}
+
+func TestResolveToken_InvalidProperty(t *testing.T) {
+	cont := container.New()
+	cont.AddPropertySource(container.MapSource{"http.port": "eighty"})
+
+	_, err := container.ResolveToken[int](cont, "http.port")
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_TYPE_ERROR, wiringErr.Code())
+	require.ErrorContains(t, err, `property 'http.port': invalid value "eighty" for int`)
+
+	var settings httpSettings
+	err = cont.FillWith(&settings, container.FillOptions{Mode: container.FillLenient})
+	require.ErrorContains(t, err, "field httpSettings.Port")
+
+	_, err = container.ResolveToken[int](cont, "missing")
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+}
+
+func TestFileSource(t *testing.T) {
+	files := map[string]string{
+		"app.yaml": "http:\n  port: 8080\n  origins: [a, b]\nratio: 0.5\n",
+		"app.json": `{"http": {"port": 8080, "origins": ["a", "b"]}, "ratio": 0.5}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+			source, err := container.FileSource(path)
+			require.NoError(t, err)
+
+			cont := container.New()
+			cont.AddPropertySource(source)
+
+			port, err := container.ResolveToken[int](cont, "http.port")
+			require.NoError(t, err)
+			require.Equal(t, 8080, port)
+
+			origins, err := container.ResolveToken[[]string](cont, "http.origins")
+			require.NoError(t, err)
+			require.Equal(t, []string{"a", "b"}, origins)
+
+			ratio, err := container.ResolveToken[float64](cont, "ratio")
+			require.NoError(t, err)
+			require.Equal(t, 0.5, ratio)
+		})
+	}
+}
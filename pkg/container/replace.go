@@ -0,0 +1,92 @@
+package container
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/graph"
+)
+
+// This file contains the logic to replace registered resolvers, mostly
+// used by tests to swap a dependency with a fake without rebuilding the
+// whole container.
+
+// Replace replaces the resolvers registered for the types returned by the
+// given resolvers keeping their lifetime. Singletons that depend on a
+// replaced resolver, directly or through other resolvers, are released so
+// they are built again with the new dependency. Only the resolvers of this
+// container are affected, singletons cached by derived containers are kept.
+func (c *Container) Replace(resolvers ...any) error {
+	for _, res := range resolvers {
+		config, err := buildConfig(res)
+		if err != nil {
+			return err
+		}
+
+		resType := config.node.Val.Type()
+		old, exists := c.typeIndex[resType]
+		if !exists {
+			return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "cannot replace dependency for %v, it's not registered", resType)
+		}
+
+		c.replaceConfig(old, config)
+		c.typeIndex[resType] = config
+	}
+
+	return c.ensureNodesConnected()
+}
+
+// ReplaceToken replaces the resolvers registered for the given tokens
+// like [Container.Replace] does for types. Resolvers registered with a
+// [Key] must return a type assignable to the type of the key.
+func (c *Container) ReplaceToken(dependencies map[string]any) error {
+	for token, res := range dependencies {
+		config, err := buildConfig(res)
+		if err != nil {
+			return err
+		}
+
+		old, exists := c.tokenIndex[token]
+		if !exists {
+			return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "cannot replace dependency for token '%s', it's not registered", token)
+		}
+
+		resType := config.node.Val.Type()
+		if old.declaredType != nil && !resType.AssignableTo(old.declaredType) {
+			return errors.Errorf(errors.E_TYPE_ERROR, "resolver for token '%s' returns %v which is not assignable to %v", token, resType, old.declaredType)
+		}
+
+		c.replaceConfig(old, config)
+		c.tokenIndex[token] = config
+	}
+
+	return c.ensureNodesConnected()
+}
+
+// replaceConfig swaps the node of old with the node of config on the graph.
+// Direct dependents of old are connected again to the new node and every
+// transitive dependent is released.
+func (c *Container) replaceConfig(old, config *resolverConfig) {
+	config.singleton = old.singleton
+	config.declaredType = old.declaredType
+	config.token = old.token
+	config.module = old.module
+
+	configs := c.configsByNode()
+	for _, node := range graph.Reachable(nil, graph.OUT, old.node) {
+		dependent := configs[node]
+		dependent.resolved = false
+		dependent.savedValue = reflect.Value{}
+	}
+
+	directDependents := old.node.GetOutgoingNodes()
+	c.pending = slices.DeleteFunc(c.pending, func(pending *resolverConfig) bool {
+		return pending == old
+	})
+	c.graph.Remove(old.node)
+	c.addNode(config)
+	for _, node := range directDependents {
+		c.pending = append(c.pending, configs[node])
+	}
+}
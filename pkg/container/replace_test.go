@@ -0,0 +1,96 @@
+package container_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+type writerHolder struct {
+	writer io.Writer
+}
+
+func TestReplace(t *testing.T) {
+	cont := container.New()
+	production := bytes.NewBufferString("production")
+	fake := bytes.NewBufferString("fake")
+
+	cont.Singleton(func() io.Writer {
+		return production
+	}, func(w io.Writer) *writerHolder {
+		return &writerHolder{writer: w}
+	}, func(holder *writerHolder) testutils.MyService {
+		return testutils.MyService{}
+	})
+
+	holder, err := container.Resolve[*writerHolder](cont)
+	require.NoError(t, err)
+	require.Same(t, production, holder.writer)
+
+	err = cont.Replace(func() io.Writer {
+		return fake
+	})
+	require.NoError(t, err)
+
+	replacedHolder, err := container.Resolve[*writerHolder](cont)
+	require.NoError(t, err)
+	require.Same(t, fake, replacedHolder.writer, "dependent singletons should be built again")
+	require.NotSame(t, holder, replacedHolder)
+
+	again, err := container.Resolve[*writerHolder](cont)
+	require.NoError(t, err)
+	require.Same(t, replacedHolder, again, "replaced resolvers should keep their lifetime")
+
+	_, err = container.Resolve[testutils.MyService](cont)
+	require.NoError(t, err)
+}
+
+func TestReplace_NotRegistered(t *testing.T) {
+	cont := container.New()
+
+	err := cont.Replace(testutils.NewService)
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+}
+
+func TestReplace_Validates(t *testing.T) {
+	cont := container.New()
+	cont.Transient(func() io.Writer {
+		return &bytes.Buffer{}
+	}, func(w io.Writer) *writerHolder {
+		return &writerHolder{writer: w}
+	})
+
+	err := cont.Replace(func(holder *writerHolder) io.Writer {
+		return holder.writer
+	})
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_CIRCULAR_DEPENDENCY, wiringErr.Code())
+}
+
+func TestReplaceToken(t *testing.T) {
+	cont := container.New()
+	bufferKey := container.NewKey[*bytes.Buffer]("buffer")
+	container.RegisterKeySingleton(cont, bufferKey, func() *bytes.Buffer {
+		return bytes.NewBufferString("production")
+	})
+
+	err := cont.ReplaceToken(map[string]any{"buffer": testutils.NewService})
+	require.Error(t, err, "replacements should match the type of the key")
+
+	err = cont.ReplaceToken(map[string]any{"buffer": func() *bytes.Buffer {
+		return bytes.NewBufferString("fake")
+	}})
+	require.NoError(t, err)
+
+	buffer, err := container.ResolveKey(cont, bufferKey)
+	require.NoError(t, err)
+	require.Equal(t, "fake", buffer.String())
+}
@@ -2,48 +2,41 @@ package container
 
 import (
 	"reflect"
-
-	"github.com/4strodev/wiring_graphs/pkg/errors"
 )
 
 func Resolve[T any](c *Container) (T, error) {
 	var dependency T
-	_, err := c.DetectCircularDependencies()
+	err := c.ensureNodesConnected()
 	if err != nil {
 		return dependency, err
 	}
 
-	resolvedValue, err := c.resolve(reflect.TypeFor[T]())
-	if err != nil {
-		return dependency, err
-	}
-
-	var ok bool
-	dependency, ok = resolvedValue.Interface().(T)
-	if !ok {
-		err = errors.Errorf(errors.E_TYPE_ERROR, "cannot convert returned value into %s", reflect.TypeFor[T]().String())
-	}
-
-	return dependency, err
+	return convertResolved[T](c.resolve(reflect.TypeFor[T](), nil))
 }
 
 func ResolveToken[T any](c *Container, token string) (T, error) {
 	var dependency T
-	_, err := c.DetectCircularDependencies()
+	err := c.ensureNodesConnected()
 	if err != nil {
 		return dependency, err
 	}
 
-	resolvedValue, err := c.resolveToken(token)
-	if err != nil {
-		return dependency, err
-	}
+	return convertResolved[T](c.resolveTokenAs(token, reflect.TypeFor[T](), nil))
+}
 
-	var ok bool
-	dependency, ok = resolvedValue.Interface().(T)
-	if !ok {
-		err = errors.Errorf(errors.E_TYPE_ERROR, "cannot convert returned value into %s", reflect.TypeFor[T]().String())
+// ResolveKey resolves the dependency registered for key
+func ResolveKey[T any](c *Container, key Key[T]) (T, error) {
+	return ResolveToken[T](c, key.name)
+}
+
+// ResolveType resolves the dependency registered for t. It's meant for
+// integrations that only know the type at runtime, prefer [Resolve]
+// otherwise.
+func (c *Container) ResolveType(t reflect.Type) (reflect.Value, error) {
+	err := c.ensureNodesConnected()
+	if err != nil {
+		return reflect.Value{}, err
 	}
 
-	return dependency, err
+	return c.resolve(t, nil)
 }
@@ -0,0 +1,103 @@
+package container_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+)
+
+type benchRoot struct{}
+
+// chainResolvers returns depth resolvers where each one depends on the
+// previous one and the last one returns a benchRoot
+func chainResolvers(depth int) []any {
+	resolvers := []any{}
+	var previous reflect.Type
+	for i := range depth {
+		levelType := reflect.StructOf([]reflect.StructField{{
+			Name: fmt.Sprintf("Level%d", i),
+			Type: reflect.TypeFor[int](),
+		}})
+		if i == depth-1 {
+			levelType = reflect.TypeFor[benchRoot]()
+		}
+
+		in := []reflect.Type{}
+		if previous != nil {
+			in = append(in, previous)
+		}
+
+		funcType := reflect.FuncOf(in, []reflect.Type{levelType}, false)
+		res := reflect.MakeFunc(funcType, func(args []reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.New(levelType).Elem()}
+		})
+
+		resolvers = append(resolvers, res.Interface())
+		previous = levelType
+	}
+
+	return resolvers
+}
+
+func benchmarkResolve(b *testing.B, depth int, singleton bool) {
+	cont := container.New()
+	resolvers := chainResolvers(depth)
+
+	var err error
+	if singleton {
+		err = cont.Singleton(resolvers...)
+	} else {
+		err = cont.Transient(resolvers...)
+	}
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		_, err := container.Resolve[benchRoot](cont)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolve_Transient10(b *testing.B) {
+	benchmarkResolve(b, 10, false)
+}
+
+func BenchmarkResolve_Transient100(b *testing.B) {
+	benchmarkResolve(b, 100, false)
+}
+
+func BenchmarkResolve_Singleton100(b *testing.B) {
+	benchmarkResolve(b, 100, true)
+}
+
+// BenchmarkDerived_RegisterAndResolve registers a dependency on a child of a
+// big container on each iteration, like a request scope would do
+func BenchmarkDerived_RegisterAndResolve(b *testing.B) {
+	cont := container.New()
+	err := cont.Singleton(chainResolvers(100)...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		derived := cont.Derived()
+		err = derived.Transient(func(root benchRoot) *int {
+			return new(int)
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		_, err := container.Resolve[*int](derived)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
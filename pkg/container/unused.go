@@ -0,0 +1,81 @@
+package container
+
+import (
+	"reflect"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/graph"
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/set"
+)
+
+// Unused returns the resolvers that are not required by any of the given
+// roots. A root is the entrypoint of an app and can be a [reflect.Type], a
+// token or a [Key]. When no root is given the resolvers resolved so far are
+// used, so calling it after running the app reports the resolvers that were
+// never needed. The resolver of the container itself is never reported.
+//
+//	unused, err := cont.Unused(reflect.TypeFor[*http.Server](), "migrations")
+func (c *Container) Unused(roots ...any) ([]*graph.Node, error) {
+	g, nodes, err := c.buildGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	rootNodes := []*graph.Node{}
+	if len(roots) == 0 {
+		for config, node := range nodes {
+			if config.resolved {
+				rootNodes = append(rootNodes, node)
+			}
+		}
+	}
+
+	for _, root := range roots {
+		node, err := lookupRoot(g, root)
+		if err != nil {
+			return nil, err
+		}
+
+		rootNodes = append(rootNodes, node)
+	}
+
+	used := set.New[*graph.Node]()
+	for _, node := range rootNodes {
+		used.Add(node)
+	}
+	for _, node := range g.TransitiveDependencies(rootNodes...) {
+		used.Add(node)
+	}
+
+	unused := []*graph.Node{}
+	for _, node := range g.Nodes() {
+		if used.Has(node) || (node.Token == "" && node.Type == reflect.TypeFor[*Container]()) {
+			continue
+		}
+
+		unused = append(unused, node)
+	}
+
+	return unused, nil
+}
+
+func lookupRoot(g *graph.Graph, root any) (*graph.Node, error) {
+	var node *graph.Node
+	var ok bool
+	switch value := root.(type) {
+	case reflect.Type:
+		node, ok = g.Lookup(value)
+	case string:
+		node, ok = g.LookupToken(value)
+	case interface{ keyName() string }:
+		node, ok = g.LookupToken(value.keyName())
+	default:
+		return nil, errors.Errorf(errors.E_TYPE_ERROR, "root should be a type, a token or a key, %T was given", root)
+	}
+
+	if !ok {
+		return nil, errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "dependency not found for root %v", root)
+	}
+
+	return node, nil
+}
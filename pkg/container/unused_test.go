@@ -0,0 +1,56 @@
+package container_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnused(t *testing.T) {
+	cont := newExplainContainer()
+	cont.Token(map[string]any{
+		"unused": func() *bytes.Buffer {
+			return bytes.NewBuffer([]byte{})
+		}},
+	)
+
+	unused, err := cont.Unused(reflect.TypeFor[*slog.Logger]())
+	require.NoError(t, err)
+	require.Equal(t, []string{"testutils.MyService", "unused(*bytes.Buffer)"}, nodeNames(unused))
+
+	unused, err = cont.Unused(reflect.TypeFor[testutils.MyService](), "unused")
+	require.NoError(t, err)
+	require.Empty(t, unused)
+
+	_, err = cont.Unused(42)
+	require.Error(t, err)
+}
+
+func TestUnused_Resolved(t *testing.T) {
+	cont := newExplainContainer()
+
+	_, err := container.Resolve[io.Writer](cont)
+	require.NoError(t, err)
+
+	unused, err := cont.Unused()
+	require.NoError(t, err)
+	require.Equal(t, []string{"*bytes.Buffer", "*slog.Logger", "testutils.MyService"}, nodeNames(unused))
+}
+
+func TestUnused_Key(t *testing.T) {
+	cont := container.New()
+	bufferKey := container.NewKey[*bytes.Buffer]("buffer")
+	container.RegisterKey(cont, bufferKey, func() *bytes.Buffer {
+		return bytes.NewBuffer([]byte{})
+	})
+
+	unused, err := cont.Unused(bufferKey)
+	require.NoError(t, err)
+	require.Empty(t, unused)
+}
@@ -0,0 +1,189 @@
+// Package containertest provides helpers to test code wired with a
+// [container.Container]. Tests work on a container derived from the
+// application one, so fakes registered by a test don't leak to other tests.
+package containertest
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/graph"
+)
+
+// New returns a container derived from base to be used by a single test.
+// The container is closed when the test and its subtests finish. Only the
+// errors returned by the closed singletons fail the test, wiring errors
+// are ignored so tests can build invalid graphs on purpose.
+func New(t testing.TB, base *container.Container) *container.Container {
+	t.Helper()
+
+	c := base.Derived()
+	t.Cleanup(func() {
+		for _, err := range closeErrors(c.Close()) {
+			t.Errorf("closing test container: %v", err)
+		}
+	})
+
+	return c
+}
+
+// closeErrors returns the errors of err that are not wiring errors
+func closeErrors(err error) []error {
+	wiringErr, ok := err.(*wiringerrors.WiringError)
+	if !ok {
+		if err == nil {
+			return nil
+		}
+		return []error{err}
+	}
+
+	joined, ok := wiringErr.Unwrap().(interface{ Unwrap() []error })
+	if wiringErr.Code() != wiringerrors.E_MULTIPLE_ERRORS || !ok {
+		return nil
+	}
+
+	errs := []error{}
+	for _, err := range joined.Unwrap() {
+		errs = append(errs, closeErrors(err)...)
+	}
+
+	return errs
+}
+
+// Override makes c resolve fake for T. Resolvers of parent containers that
+// depend on T are built with fake when resolved from c. See
+// [container.Container.Override].
+func Override[T any](t testing.TB, c *container.Container, fake T) {
+	t.Helper()
+
+	err := c.Override(func() T {
+		return fake
+	})
+	if err != nil {
+		t.Fatalf("overriding %v: %v", reflect.TypeFor[T](), err)
+	}
+}
+
+// AssertResolvable fails the test when T cannot be resolved from c and
+// returns the resolved value
+func AssertResolvable[T any](t testing.TB, c *container.Container) T {
+	t.Helper()
+
+	value, err := container.Resolve[T](c)
+	if err != nil {
+		t.Fatalf("%v should be resolvable: %v", reflect.TypeFor[T](), err)
+	}
+
+	return value
+}
+
+// AssertSingleton fails the test when T is not registered as a singleton
+// on c or any of its parents
+func AssertSingleton[T any](t testing.TB, c *container.Container) {
+	t.Helper()
+
+	g, err := c.Graph()
+	if err != nil {
+		t.Fatalf("building graph: %v", err)
+	}
+
+	node, ok := g.Lookup(reflect.TypeFor[T]())
+	if !ok {
+		t.Fatalf("%v is not registered", reflect.TypeFor[T]())
+	}
+
+	if node.Lifetime != graph.Singleton {
+		t.Errorf("%v should be a singleton but it's %v", node, node.Lifetime)
+	}
+}
+
+// AssertNoCycles fails the test when the dependencies registered on c have
+// a circular dependency
+func AssertNoCycles(t testing.TB, c *container.Container) {
+	t.Helper()
+
+	cycle, err := c.DetectCircularDependencies()
+	if err != nil {
+		t.Fatalf("circular dependencies should not be detected: %v", err)
+	}
+	if len(cycle) > 0 {
+		t.Fatalf("circular dependencies should not be detected: %v", cycle)
+	}
+}
+
+// Recorder records the dependencies resolved by a container. It's safe to
+// use from multiple goroutines.
+type Recorder struct {
+	mu     sync.Mutex
+	events []container.ResolveEvent
+}
+
+// Record returns a recorder of the dependencies resolved by c from now on
+func Record(c *container.Container) *Recorder {
+	r := &Recorder{}
+	c.OnResolve(func(event container.ResolveEvent) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, event)
+	})
+
+	return r
+}
+
+// Events returns the resolved dependencies in the order they were resolved
+func (r *Recorder) Events() []container.ResolveEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.events)
+}
+
+// Count returns how many times a dependency of type t was resolved by type
+func (r *Recorder) Count(t reflect.Type) int {
+	return r.count(func(event container.ResolveEvent) bool {
+		return event.Token == "" && event.Type == t
+	})
+}
+
+// CountToken returns how many times token was resolved
+func (r *Recorder) CountToken(token string) int {
+	return r.count(func(event container.ResolveEvent) bool {
+		return event.Token == token
+	})
+}
+
+func (r *Recorder) count(match func(container.ResolveEvent) bool) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, event := range r.events {
+		if match(event) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// AssertResolved fails the test when T was not resolved by type
+func AssertResolved[T any](t testing.TB, r *Recorder) {
+	t.Helper()
+
+	if r.Count(reflect.TypeFor[T]()) == 0 {
+		t.Errorf("%v should have been resolved", reflect.TypeFor[T]())
+	}
+}
+
+// AssertNotResolved fails the test when T was resolved by type
+func AssertNotResolved[T any](t testing.TB, r *Recorder) {
+	t.Helper()
+
+	count := r.Count(reflect.TypeFor[T]())
+	if count > 0 {
+		t.Errorf("%v should not have been resolved but it was resolved %d times", reflect.TypeFor[T](), count)
+	}
+}
@@ -0,0 +1,149 @@
+package containertest_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	"github.com/4strodev/wiring_graphs/pkg/containertest"
+	"github.com/4strodev/wiring_graphs/pkg/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+type closer struct {
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
+
+type writerHolder struct {
+	writer io.Writer
+}
+
+// recordingT records the failures reported by the assertions
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func newBase() *container.Container {
+	base := container.New()
+	base.Singleton(func() io.Writer {
+		return bytes.NewBufferString("production")
+	}, func(w io.Writer) *writerHolder {
+		return &writerHolder{writer: w}
+	})
+	base.Transient(testutils.NewService)
+
+	return base
+}
+
+func TestNew(t *testing.T) {
+	base := newBase()
+	c := &closer{}
+
+	t.Run("closes the container", func(t *testing.T) {
+		cont := containertest.New(t, base)
+		err := cont.Singleton(func() *closer {
+			return c
+		})
+		require.NoError(t, err)
+		containertest.AssertResolvable[*closer](t, cont)
+	})
+
+	require.True(t, c.closed, "test container should be closed at cleanup")
+	_, err := container.Resolve[*closer](base)
+	require.Error(t, err, "registrations should not leak to the base container")
+}
+
+type failingCloser struct{}
+
+func (failingCloser) Close() error {
+	return errors.New("close failed")
+}
+
+func TestNew_CloseErrors(t *testing.T) {
+	recorder := &recordingT{}
+	t.Run("invalid graph", func(t *testing.T) {
+		recorder.TB = t
+		cont := containertest.New(recorder, newBase())
+		cont.Singleton(func() failingCloser {
+			return failingCloser{}
+		})
+		containertest.AssertResolvable[failingCloser](t, cont)
+
+		cont.Transient(func(r io.Reader) *closer {
+			return &closer{}
+		})
+		_, err := container.Resolve[*closer](cont)
+		require.Error(t, err)
+	})
+
+	require.Len(t, recorder.errors, 1, "only errors of the closed singletons should be reported")
+	require.Contains(t, recorder.errors[0], "close failed")
+}
+
+func TestOverride(t *testing.T) {
+	base := newBase()
+	production := containertest.AssertResolvable[*writerHolder](t, base)
+
+	cont := containertest.New(t, base)
+	fake := &bytes.Buffer{}
+	containertest.Override[io.Writer](t, cont, fake)
+
+	holder := containertest.AssertResolvable[*writerHolder](t, cont)
+	require.Same(t, fake, holder.writer)
+	require.Same(t, production, containertest.AssertResolvable[*writerHolder](t, base))
+}
+
+func TestOverride_BaseInjectsContainer(t *testing.T) {
+	base := newBase()
+	base.Transient(func(c *container.Container) *bytes.Reader {
+		return bytes.NewReader([]byte{})
+	})
+
+	cont := containertest.New(t, base)
+	containertest.AssertSingleton[*writerHolder](t, cont)
+
+	fake := &bytes.Buffer{}
+	containertest.Override[io.Writer](t, cont, fake)
+	holder := containertest.AssertResolvable[*writerHolder](t, cont)
+	require.Same(t, fake, holder.writer)
+}
+
+func TestAssertions(t *testing.T) {
+	cont := containertest.New(t, newBase())
+
+	containertest.AssertNoCycles(t, cont)
+	containertest.AssertSingleton[*writerHolder](t, cont)
+
+	recorder := &recordingT{TB: t}
+	containertest.AssertSingleton[testutils.MyService](recorder, cont)
+	require.Len(t, recorder.errors, 1, "transient dependencies should fail the assertion")
+}
+
+func TestRecorder(t *testing.T) {
+	cont := containertest.New(t, newBase())
+	recorder := containertest.Record(cont)
+
+	containertest.AssertResolvable[*writerHolder](t, cont)
+	containertest.AssertResolvable[*writerHolder](t, cont)
+
+	require.Equal(t, 2, recorder.Count(reflect.TypeFor[*writerHolder]()))
+	require.Len(t, recorder.Events(), 2)
+	containertest.AssertResolved[*writerHolder](t, recorder)
+	containertest.AssertNotResolved[testutils.MyService](t, recorder)
+}
@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type wiringErrorCode int
 
@@ -10,6 +13,10 @@ const (
 	E_REDECLARED_DEPENDENCY
 	E_DEPENDENCY_NOT_FOUND
 	E_TYPE_ERROR
+	E_MULTIPLE_ERRORS
+	E_POST_CONSTRUCT
+	E_DUPLICATE_MODULE
+	E_INVALID_CONFIG
 )
 
 type WiringError struct {
@@ -25,6 +32,23 @@ func Errorf(code wiringErrorCode, format string, values ...any) *WiringError {
 	}
 }
 
+// Join returns an error with code [E_MULTIPLE_ERRORS] wrapping all the
+// given errors. When errs has only one error it is returned as is and when
+// there is none it returns nil.
+func Join(errs ...error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+
+	return &WiringError{
+		err:  errors.Join(errs...),
+		code: E_MULTIPLE_ERRORS,
+	}
+}
+
 func (e *WiringError) Error() string {
 	return e.err.Error()
 }
@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/queue"
+)
+
+// PathBetween returns the shortest chain of dependencies that makes from
+// depend on to, starting with from and ending with to. It returns nil when
+// from does not depend on to.
+func (g *Graph) PathBetween(from, to *Node) []*Node {
+	previous := map[*Node]*Node{from: nil}
+	pending := queue.Queue[*Node]{}
+	pending.Push(from)
+
+	for !pending.IsEmpty() {
+		node, _ := pending.Pop()
+		if node == to {
+			path := []*Node{}
+			for current := to; current != nil; current = previous[current] {
+				path = append(path, current)
+			}
+			slices.Reverse(path)
+
+			return path
+		}
+
+		for _, dependency := range node.Dependencies() {
+			_, visited := previous[dependency]
+			if visited {
+				continue
+			}
+
+			previous[dependency] = node
+			pending.Push(dependency)
+		}
+	}
+
+	return nil
+}
+
+// WriteDependencies writes the tree of dependencies of node indenting each
+// level with two spaces
+//
+//	*main.Handler
+//	  *main.Service
+//	    *sql.DB
+func WriteDependencies(w io.Writer, node *Node) error {
+	return writeTree(w, node, 0, (*Node).Dependencies)
+}
+
+// WriteDependents writes the tree of dependents of node indenting each
+// level with two spaces
+func WriteDependents(w io.Writer, node *Node) error {
+	return writeTree(w, node, 0, (*Node).Dependents)
+}
+
+func writeTree(w io.Writer, node *Node, depth int, children func(*Node) []*Node) error {
+	_, err := fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), node)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children(node) {
+		err = writeTree(w, child, depth+1, children)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+// Package graph exposes a read only view of the dependency graph of a
+// container. Edges go from a dependency to its dependents, so root nodes
+// have no dependencies and leaves are not required by any other node.
+package graph
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/graph"
+)
+
+type Lifetime int
+
+const (
+	Transient Lifetime = iota
+	Singleton
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	default:
+		return "transient"
+	}
+}
+
+// Node is a registered resolver
+type Node struct {
+	// Type is the type returned by the resolver
+	Type reflect.Type
+	// Token is the token used to register the resolver, empty when the
+	// resolver was registered by type
+	Token    string
+	Lifetime Lifetime
+	// Module is the name of the module that registered the resolver,
+	// empty when it was registered directly on the container
+	Module string
+	// Private nodes can only be injected into nodes of the same module and
+	// are not returned by [Graph.Lookup]
+	Private bool
+
+	node *graph.Node[*Node]
+}
+
+// Dependencies returns the nodes this node depends on (incoming edges)
+func (n *Node) Dependencies() []*Node {
+	return values(n.node.GetIncomingNodes())
+}
+
+// Dependents returns the nodes that depend on this node (outgoing edges)
+func (n *Node) Dependents() []*Node {
+	return values(n.node.GetOutgoingNodes())
+}
+
+// String returns the type of the node prefixed by its token if any and
+// followed by its module if any
+//
+//	*bytes.Buffer
+//	buffer(*bytes.Buffer)
+//	*sql.DB [database]
+func (n *Node) String() string {
+	name := n.Type.String()
+	if n.Token != "" {
+		name = n.Token + "(" + name + ")"
+	}
+
+	if n.Module != "" {
+		name += " [" + n.Module + "]"
+	}
+
+	return name
+}
+
+// compare sorts nodes by their representation. Types with the same name
+// are sorted by their package path.
+func compare(a, b *Node) int {
+	result := strings.Compare(a.String(), b.String())
+	if result != 0 {
+		return result
+	}
+
+	return strings.Compare(a.Type.PkgPath(), b.Type.PkgPath())
+}
+
+func compareInternal(a, b *graph.Node[*Node]) int {
+	return compare(a.Val, b.Val)
+}
+
+// values returns the public nodes of internal nodes sorted
+func values(nodes []*graph.Node[*Node]) []*Node {
+	result := make([]*Node, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, node.Val)
+	}
+	slices.SortFunc(result, compare)
+
+	return result
+}
+
+// internals returns the internal nodes of public nodes
+func internals(nodes []*Node) []*graph.Node[*Node] {
+	result := make([]*graph.Node[*Node], 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, node.node)
+	}
+
+	return result
+}
+
+// Graph is an immutable dependency graph. Every slice returned by it is
+// sorted so iteration order is stable.
+type Graph struct {
+	nodes []*Node
+	graph graph.Graph[*Node]
+}
+
+// Nodes returns all the nodes of the graph
+func (g *Graph) Nodes() []*Node {
+	return slices.Clone(g.nodes)
+}
+
+// Roots returns the nodes without dependencies
+func (g *Graph) Roots() []*Node {
+	return g.filter(func(n *Node) bool {
+		return !n.node.HasIncomingNodes()
+	})
+}
+
+// Leaves returns the nodes without dependents
+func (g *Graph) Leaves() []*Node {
+	return g.filter(func(n *Node) bool {
+		return !n.node.HasOutgoingNodes()
+	})
+}
+
+// Lookup returns the node registered for t
+func (g *Graph) Lookup(t reflect.Type) (*Node, bool) {
+	for _, node := range g.nodes {
+		if node.Token == "" && !node.Private && node.Type == t {
+			return node, true
+		}
+	}
+
+	return nil, false
+}
+
+// LookupToken returns the node registered for token
+func (g *Graph) LookupToken(token string) (*Node, bool) {
+	for _, node := range g.nodes {
+		if node.Token != "" && node.Token == token {
+			return node, true
+		}
+	}
+
+	return nil, false
+}
+
+func (g *Graph) filter(keep func(n *Node) bool) []*Node {
+	nodes := []*Node{}
+	for _, node := range g.nodes {
+		if keep(node) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+// TopologicalSort returns the nodes sorted so every node goes after its
+// dependencies. It's the order in which dependencies can be built, the
+// reverse order is the one in which they can be disposed.
+func (g *Graph) TopologicalSort() ([]*Node, error) {
+	sorted, ok := g.graph.TopologicalSort(compareInternal)
+	if !ok {
+		return nil, errors.Errorf(errors.E_CIRCULAR_DEPENDENCY, "cannot sort a graph with circular dependencies")
+	}
+
+	return valuesInOrder(sorted), nil
+}
+
+// Layers groups the nodes by their depth. The first layer holds the roots
+// and every node is placed one layer after the last of its dependencies, so
+// the nodes of a layer can be built concurrently once the previous layers
+// are built.
+func (g *Graph) Layers() ([][]*Node, error) {
+	layers, ok := g.graph.Layers(compareInternal)
+	if !ok {
+		return nil, errors.Errorf(errors.E_CIRCULAR_DEPENDENCY, "cannot group a graph with circular dependencies")
+	}
+
+	result := make([][]*Node, 0, len(layers))
+	for _, layer := range layers {
+		result = append(result, valuesInOrder(layer))
+	}
+
+	return result, nil
+}
+
+// TransitiveDependencies returns every node required to build the given
+// nodes, directly or through other nodes
+func (g *Graph) TransitiveDependencies(nodes ...*Node) []*Node {
+	return valuesInOrder(graph.Reachable(compareInternal, graph.IN, internals(nodes)...))
+}
+
+// TransitiveDependents returns every node that requires any of the given
+// nodes, directly or through other nodes
+func (g *Graph) TransitiveDependents(nodes ...*Node) []*Node {
+	return valuesInOrder(graph.Reachable(compareInternal, graph.OUT, internals(nodes)...))
+}
+
+// TransitiveReduction returns a copy of the graph without the edges implied
+// by other paths. When A depends on B and C, and B depends on C, the edge
+// from C to A is removed. The graph must not have circular dependencies.
+func (g *Graph) TransitiveReduction() *Graph {
+	redundant := map[*Node]map[*Node]bool{}
+	for _, edge := range g.graph.RedundantEdges() {
+		if redundant[edge.From.Val] == nil {
+			redundant[edge.From.Val] = map[*Node]bool{}
+		}
+		redundant[edge.From.Val][edge.To.Val] = true
+	}
+
+	builder := Builder{}
+	copies := map[*Node]*Node{}
+	for _, node := range g.nodes {
+		clone := *node
+		copies[node] = builder.add(&clone)
+	}
+
+	for _, node := range g.nodes {
+		for _, dependent := range node.Dependents() {
+			if !redundant[node][dependent] {
+				builder.Connect(copies[node], copies[dependent])
+			}
+		}
+	}
+
+	return builder.Build()
+}
+
+// valuesInOrder returns the public nodes of internal nodes keeping the order
+func valuesInOrder(nodes []*graph.Node[*Node]) []*Node {
+	result := make([]*Node, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, node.Val)
+	}
+
+	return result
+}
+
+// Builder creates a [Graph]. It's used by the container to export its graph
+// and should not be used after calling [Builder.Build].
+type Builder struct {
+	nodes []*Node
+	graph graph.Graph[*Node]
+}
+
+// Add adds a node to the graph
+func (b *Builder) Add(t reflect.Type, token string, lifetime Lifetime) *Node {
+	return b.add(&Node{
+		Type:     t,
+		Token:    token,
+		Lifetime: lifetime,
+	})
+}
+
+// add adds node to the graph replacing its internal node
+func (b *Builder) add(node *Node) *Node {
+	if len(b.nodes) == 0 {
+		b.graph = graph.NewGraph[*Node]()
+	}
+
+	node.node = graph.NewNode(node)
+	b.graph.Add(node.node)
+	b.nodes = append(b.nodes, node)
+
+	return node
+}
+
+// Connect adds an edge from dependency to dependent
+func (b *Builder) Connect(dependency, dependent *Node) {
+	b.graph.Connect(dependency.node, dependent.node, graph.OUT)
+}
+
+// Build returns the graph with every node sorted
+func (b *Builder) Build() *Graph {
+	if len(b.nodes) == 0 {
+		b.graph = graph.NewGraph[*Node]()
+	}
+
+	nodes := slices.Clone(b.nodes)
+	slices.SortFunc(nodes, compare)
+
+	return &Graph{
+		nodes: nodes,
+		graph: b.graph,
+	}
+}
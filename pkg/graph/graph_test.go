@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	builder := Builder{}
+	writer := builder.Add(reflect.TypeFor[io.Writer](), "", Singleton)
+	buffer := builder.Add(reflect.TypeFor[*bytes.Buffer](), "buffer", Transient)
+	reader := builder.Add(reflect.TypeFor[io.Reader](), "", Transient)
+
+	builder.Connect(writer, buffer)
+	builder.Connect(writer, buffer)
+	builder.Connect(reader, buffer)
+	g := builder.Build()
+
+	require.Equal(t, []*Node{buffer, reader, writer}, g.Nodes(), "nodes should be sorted")
+	require.Equal(t, []*Node{reader, writer}, g.Roots())
+	require.Equal(t, []*Node{buffer}, g.Leaves())
+	require.Equal(t, []*Node{reader, writer}, buffer.Dependencies(), "edges should not be duplicated")
+	require.Equal(t, []*Node{buffer}, writer.Dependents())
+
+	node, ok := g.LookupToken("buffer")
+	require.True(t, ok)
+	require.Equal(t, "buffer(*bytes.Buffer)", node.String())
+
+	_, ok = g.Lookup(reflect.TypeFor[*bytes.Buffer]())
+	require.False(t, ok, "nodes registered by token should not be found by type")
+}
+
+func TestTopology(t *testing.T) {
+	builder := Builder{}
+	writer := builder.Add(reflect.TypeFor[io.Writer](), "", Singleton)
+	reader := builder.Add(reflect.TypeFor[io.Reader](), "", Singleton)
+	buffer := builder.Add(reflect.TypeFor[*bytes.Buffer](), "", Transient)
+	buffer.Module = "buffers"
+	buffer.Private = true
+
+	// buffer depends on reader and writer, reader depends on writer
+	builder.Connect(writer, reader)
+	builder.Connect(writer, buffer)
+	builder.Connect(reader, buffer)
+	g := builder.Build()
+
+	sorted, err := g.TopologicalSort()
+	require.NoError(t, err)
+	require.Equal(t, []*Node{writer, reader, buffer}, sorted)
+
+	layers, err := g.Layers()
+	require.NoError(t, err)
+	require.Equal(t, [][]*Node{{writer}, {reader}, {buffer}}, layers)
+
+	require.Equal(t, []*Node{reader, writer}, g.TransitiveDependencies(buffer))
+	require.Equal(t, []*Node{buffer, reader}, g.TransitiveDependents(writer))
+
+	reduced := g.TransitiveReduction()
+	reducedWriter, _ := reduced.Lookup(reflect.TypeFor[io.Writer]())
+	require.Len(t, reducedWriter.Dependents(), 1, "writer -> buffer is implied by writer -> reader -> buffer")
+	require.Len(t, writer.Dependents(), 2, "the original graph should not change")
+
+	_, ok := reduced.Lookup(reflect.TypeFor[*bytes.Buffer]())
+	require.False(t, ok, "private nodes should be kept private")
+	reducedBuffer := reduced.Nodes()[0]
+	require.Equal(t, "buffers", reducedBuffer.Module)
+	require.True(t, reducedBuffer.Private)
+}
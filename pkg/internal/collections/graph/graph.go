@@ -18,6 +18,15 @@ func (g *Graph[T]) Add(node *Node[T]) {
 	g.nodes.Add(node)
 }
 
+// Remove disconnects node from every other node and removes it from
+// the graph
+func (g *Graph[T]) Remove(node *Node[T]) {
+	for neighbour := range node.connections {
+		node.Disconnect(neighbour)
+	}
+	g.nodes.Remove(node)
+}
+
 func (g Graph[T]) Connect(originNode, destinationNode *Node[T], dir connectionDirection) {
 	g.Add(originNode)
 	g.Add(destinationNode)
@@ -42,13 +51,27 @@ func (g Graph[T]) GetRootNodes() []*Node[T] {
 
 // DetectCircularRelations using BFS detects circular relations between nodes on this graph
 func (g Graph[T]) DetectCircularRelations() ([]*Node[T], bool) {
+	nodes := make([]*Node[T], 0, len(g.nodes))
 	for node := range g.nodes {
-		if !node.HasConnections() {
+		nodes = append(nodes, node)
+	}
+
+	return g.DetectCircularRelationsFrom(nodes...)
+}
+
+// DetectCircularRelationsFrom detects circular relations reachable from the
+// given nodes. When the graph had no cycles before adding some edges, only
+// the nodes with new outgoing edges need to be checked.
+func (g Graph[T]) DetectCircularRelationsFrom(nodes ...*Node[T]) ([]*Node[T], bool) {
+	// nodes visited from a previous node can't be part of a new cycle
+	// so the state is shared across all the searches
+	state := map[*Node[T]]dfsStates{} // 0: Unvisited, 1: Visiting, 2: Visited
+	parent := map[*Node[T]]*Node[T]{}
+	for _, node := range nodes {
+		if !node.HasConnections() || state[node] == VISITED {
 			continue
 		}
 
-		state := map[*Node[T]]dfsStates{} // 0: Unvisited, 1: Visiting, 2: Visited
-		parent := map[*Node[T]]*Node[T]{}
 		var cycle []*Node[T]
 		found := dfsVisit(node, state, parent, &cycle)
 		if found {
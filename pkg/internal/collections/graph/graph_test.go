@@ -0,0 +1,34 @@
+package graph
+
+import "testing"
+
+func TestDetectCircularRelations(t *testing.T) {
+	g := NewGraph[string]()
+	nodeA := NewNode("A")
+	nodeB := NewNode("B")
+	nodeC := NewNode("C")
+	nodeD := NewNode("D")
+
+	// diamond A -> B -> D, A -> C -> D
+	g.Connect(nodeA, nodeB, OUT)
+	g.Connect(nodeA, nodeC, OUT)
+	g.Connect(nodeB, nodeD, OUT)
+	g.Connect(nodeC, nodeD, OUT)
+
+	_, found := g.DetectCircularRelations()
+	if found {
+		t.Fatalf("Cycle should not be detected on a diamond\n")
+	}
+
+	nodeE := NewNode("E")
+	g.Connect(nodeD, nodeE, OUT)
+	g.Connect(nodeE, nodeC, OUT)
+
+	cycle, found := g.DetectCircularRelations()
+	if !found {
+		t.Fatalf("Cycle should be detected\n")
+	}
+	if cycle[0] != cycle[len(cycle)-1] {
+		t.Fatalf("Cycle should start and end on the same node\n")
+	}
+}
@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"slices"
+
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/queue"
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/set"
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/stack"
+)
+
+// Compare orders nodes to get deterministic results. A nil Compare keeps
+// the order of the underlying maps.
+type Compare[T any] func(a, b *Node[T]) int
+
+func (c Compare[T]) sort(nodes []*Node[T]) []*Node[T] {
+	if c != nil {
+		slices.SortFunc(nodes, c)
+	}
+
+	return nodes
+}
+
+// TopologicalSort sorts the nodes using Kahn's algorithm so every node goes
+// after the nodes it has incoming connections from. It returns false when
+// the graph has cycles.
+func (g Graph[T]) TopologicalSort(compare Compare[T]) ([]*Node[T], bool) {
+	layers, ok := g.Layers(compare)
+	if !ok {
+		return nil, false
+	}
+
+	return slices.Concat(layers...), true
+}
+
+// Layers groups the nodes by their distance to the root nodes. The first
+// layer holds the root nodes and each node is placed one layer after the
+// last of its incoming nodes, so nodes on the same layer don't depend on
+// each other. It returns false when the graph has cycles.
+func (g Graph[T]) Layers(compare Compare[T]) ([][]*Node[T], bool) {
+	pending := map[*Node[T]]int{}
+	ready := queue.Queue[*Node[T]]{}
+	for _, node := range compare.sort(g.GetRootNodes()) {
+		ready.Push(node)
+	}
+	for node := range g.nodes {
+		pending[node] = len(node.GetIncomingNodes())
+	}
+
+	layers := [][]*Node[T]{}
+	sorted := 0
+	for !ready.IsEmpty() {
+		layer := []*Node[T]{}
+		next := []*Node[T]{}
+		for !ready.IsEmpty() {
+			node, _ := ready.Pop()
+			layer = append(layer, node)
+
+			for _, outgoing := range node.GetOutgoingNodes() {
+				pending[outgoing]--
+				if pending[outgoing] == 0 {
+					next = append(next, outgoing)
+				}
+			}
+		}
+
+		for _, node := range compare.sort(next) {
+			ready.Push(node)
+		}
+		sorted += len(layer)
+		layers = append(layers, layer)
+	}
+
+	if sorted != len(g.nodes) {
+		return nil, false
+	}
+
+	return layers, true
+}
+
+// Reachable returns the nodes reachable from the given nodes following the
+// connections on direction dir, [OUT] or [IN]. The given nodes are only
+// included when they are reachable from another of them.
+func Reachable[T any](compare Compare[T], dir connectionDirection, from ...*Node[T]) []*Node[T] {
+	visited := set.New[*Node[T]]()
+	reachable := []*Node[T]{}
+	pending := stack.Stack[*Node[T]]{}
+	for _, node := range from {
+		pending.Add(node)
+	}
+
+	for !pending.IsEmpty() {
+		node, _ := pending.Pop()
+
+		neighbours := node.GetOutgoingNodes()
+		if dir == IN {
+			neighbours = node.GetIncomingNodes()
+		}
+
+		for _, neighbour := range neighbours {
+			if visited.Has(neighbour) {
+				continue
+			}
+
+			visited.Add(neighbour)
+			reachable = append(reachable, neighbour)
+			pending.Add(neighbour)
+		}
+	}
+
+	return compare.sort(reachable)
+}
+
+// Edge is a connection from a node to another
+type Edge[T any] struct {
+	From *Node[T]
+	To   *Node[T]
+}
+
+// RedundantEdges returns the edges that can be removed without changing
+// the reachability of the graph. Removing them gives the transitive
+// reduction of the graph, which must not have cycles.
+func (g Graph[T]) RedundantEdges() []Edge[T] {
+	redundant := []Edge[T]{}
+	for node := range g.nodes {
+		outgoing := node.GetOutgoingNodes()
+		for _, neighbour := range outgoing {
+			others := slices.DeleteFunc(slices.Clone(outgoing), func(n *Node[T]) bool {
+				return n == neighbour
+			})
+
+			reachable := Reachable(nil, OUT, others...)
+			if slices.Contains(reachable, neighbour) {
+				redundant = append(redundant, Edge[T]{From: node, To: neighbour})
+			}
+		}
+	}
+
+	return redundant
+}
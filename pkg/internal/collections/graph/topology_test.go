@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func compareStrings(a, b *Node[string]) int {
+	return strings.Compare(a.Val, b.Val)
+}
+
+func names(nodes []*Node[string]) []string {
+	result := []string{}
+	for _, node := range nodes {
+		result = append(result, node.Val)
+	}
+
+	return result
+}
+
+// newTestGraph returns the graph
+//
+//	A -> B -> D
+//	A -> C -> D
+//	A -> D
+func newTestGraph() (Graph[string], map[string]*Node[string]) {
+	g := NewGraph[string]()
+	nodes := map[string]*Node[string]{}
+	for _, name := range []string{"A", "B", "C", "D"} {
+		nodes[name] = NewNode(name)
+		g.Add(nodes[name])
+	}
+
+	g.Connect(nodes["A"], nodes["B"], OUT)
+	g.Connect(nodes["A"], nodes["C"], OUT)
+	g.Connect(nodes["A"], nodes["D"], OUT)
+	g.Connect(nodes["B"], nodes["D"], OUT)
+	g.Connect(nodes["C"], nodes["D"], OUT)
+
+	return g, nodes
+}
+
+func TestTopologicalSort(t *testing.T) {
+	g, nodes := newTestGraph()
+
+	sorted, ok := g.TopologicalSort(compareStrings)
+	if !ok {
+		t.Fatalf("Graph without cycles should be sorted\n")
+	}
+	if !slices.Equal(names(sorted), []string{"A", "B", "C", "D"}) {
+		t.Fatalf("Unexpected order %v\n", names(sorted))
+	}
+
+	g.Connect(nodes["D"], nodes["A"], OUT)
+	_, ok = g.TopologicalSort(compareStrings)
+	if ok {
+		t.Fatalf("Graph with cycles should not be sorted\n")
+	}
+}
+
+func TestLayers(t *testing.T) {
+	g, _ := newTestGraph()
+
+	layers, ok := g.Layers(compareStrings)
+	if !ok {
+		t.Fatalf("Graph without cycles should be grouped\n")
+	}
+
+	expected := [][]string{{"A"}, {"B", "C"}, {"D"}}
+	if len(layers) != len(expected) {
+		t.Fatalf("Expected %d layers got %d\n", len(expected), len(layers))
+	}
+	for i := range layers {
+		if !slices.Equal(names(layers[i]), expected[i]) {
+			t.Fatalf("Unexpected layer %d %v\n", i, names(layers[i]))
+		}
+	}
+}
+
+func TestReachable(t *testing.T) {
+	_, nodes := newTestGraph()
+
+	reachable := Reachable(compareStrings, OUT, nodes["B"])
+	if !slices.Equal(names(reachable), []string{"D"}) {
+		t.Fatalf("Unexpected reachable nodes %v\n", names(reachable))
+	}
+
+	reachable = Reachable(compareStrings, IN, nodes["D"])
+	if !slices.Equal(names(reachable), []string{"A", "B", "C"}) {
+		t.Fatalf("Unexpected reachable nodes %v\n", names(reachable))
+	}
+}
+
+func TestRedundantEdges(t *testing.T) {
+	g, nodes := newTestGraph()
+
+	redundant := g.RedundantEdges()
+	if len(redundant) != 1 || redundant[0].From != nodes["A"] || redundant[0].To != nodes["D"] {
+		t.Fatalf("Only A -> D should be redundant\n")
+	}
+}
@@ -0,0 +1,125 @@
+// Package convert converts textual values, like environment variables or
+// flags, to Go values
+package convert
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Supported reports if values of type t can be converted from a string
+func Supported(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Pointer:
+		return Supported(t.Elem())
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Slice && Supported(t.Elem())
+	default:
+		return false
+	}
+}
+
+// FromString converts s to a value of type t. Besides the basic types it
+// supports [time.Duration], types implementing [encoding.TextUnmarshaler],
+// pointers to supported types and slices of them, which are read as comma
+// separated values.
+func FromString(s string, t reflect.Type) (reflect.Value, error) {
+	value := reflect.New(t).Elem()
+
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		err := unmarshaler.UnmarshalText([]byte(s))
+		if err != nil {
+			return value, fmt.Errorf("invalid value %q for %v: %w", s, t, err)
+		}
+		return value, nil
+	}
+
+	if t == durationType {
+		duration, err := time.ParseDuration(s)
+		if err != nil {
+			return value, invalid(s, t)
+		}
+		value.SetInt(int64(duration))
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return value, invalid(s, t)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return value, invalid(s, t)
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return value, invalid(s, t)
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return value, invalid(s, t)
+		}
+		value.SetFloat(f)
+	case reflect.Pointer:
+		elem, err := FromString(s, t.Elem())
+		if err != nil {
+			return value, err
+		}
+		value.Set(reflect.New(t.Elem()))
+		value.Elem().Set(elem)
+	case reflect.Slice:
+		if !Supported(t) {
+			return value, fmt.Errorf("cannot convert a string to %v", t)
+		}
+		if strings.TrimSpace(s) == "" {
+			value.Set(reflect.MakeSlice(t, 0, 0))
+			return value, nil
+		}
+
+		items := strings.Split(s, ",")
+		value.Set(reflect.MakeSlice(t, len(items), len(items)))
+		for i, item := range items {
+			elem, err := FromString(strings.TrimSpace(item), t.Elem())
+			if err != nil {
+				return value, err
+			}
+			value.Index(i).Set(elem)
+		}
+	default:
+		return value, fmt.Errorf("cannot convert a string to %v", t)
+	}
+
+	return value, nil
+}
+
+func invalid(s string, t reflect.Type) error {
+	return fmt.Errorf("invalid value %q for %v", s, t)
+}
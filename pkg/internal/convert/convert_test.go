@@ -0,0 +1,62 @@
+package convert
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFromString(t *testing.T) {
+	port := 8080
+	cases := []struct {
+		value    string
+		expected any
+	}{
+		{"text", "text"},
+		{"true", true},
+		{"8080", 8080},
+		{"-3", int8(-3)},
+		{"42", uint(42)},
+		{"1.5", 1.5},
+		{"1m30s", 90 * time.Second},
+		{"8080", &port},
+		{"a, b,c", []string{"a", "b", "c"}},
+		{"1,2", []int{1, 2}},
+		{"", []string{}},
+		{"127.0.0.1", netip.MustParseAddr("127.0.0.1")},
+	}
+
+	for _, c := range cases {
+		expectedType := reflect.TypeOf(c.expected)
+		value, err := FromString(c.value, expectedType)
+		if err != nil {
+			t.Fatalf("converting %q to %v: %v", c.value, expectedType, err)
+		}
+
+		if !reflect.DeepEqual(c.expected, value.Interface()) {
+			t.Fatalf("expected %v converting %q, got %v", c.expected, c.value, value.Interface())
+		}
+	}
+}
+
+func TestFromString_Invalid(t *testing.T) {
+	cases := []struct {
+		value string
+		t     reflect.Type
+	}{
+		{"eighty", reflect.TypeFor[int]()},
+		{"300", reflect.TypeFor[uint8]()},
+		{"yes please", reflect.TypeFor[bool]()},
+		{"10 minutes", reflect.TypeFor[time.Duration]()},
+		{"1,x", reflect.TypeFor[[]int]()},
+		{"{}", reflect.TypeFor[map[string]string]()},
+	}
+
+	for _, c := range cases {
+		_, err := FromString(c.value, c.t)
+		if err == nil {
+			t.Fatalf("converting %q to %v should fail", c.value, c.t)
+		}
+	}
+}
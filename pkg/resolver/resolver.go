@@ -29,6 +29,10 @@ func (d DependencyResolver[T]) Input() []reflect.Type {
 }
 
 func IsValid(resolver any) bool {
+	if resolver == nil || reflect.TypeOf(resolver).Kind() != reflect.Func {
+		return false
+	}
+
 	return canBeSimpleResolver(resolver) || canBeErrorResolver(resolver)
 }
 
@@ -0,0 +1,203 @@
+package wiringgen
+
+import (
+	"bytes"
+	"go/format"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+)
+
+const containerImport = "github.com/4strodev/wiring_graphs/pkg/container"
+
+// Options customizes the generated code
+type Options struct {
+	// TypeName is the name of the generated struct. Defaults to Wiring.
+	TypeName string
+}
+
+type generatedProvider struct {
+	*Provider
+	// Method is the name of the accessor method
+	Method string
+	// Field is the prefix of the fields that hold the singleton state
+	Field string
+	// Args are the accessor methods called to get each parameter
+	Args []string
+}
+
+type generatedPackage struct {
+	Name     string
+	TypeName string
+	// StdImports holds the imports of the standard library which are
+	// grouped apart
+	StdImports []string
+	Imports    []string
+	Providers  []generatedProvider
+}
+
+var generatedTemplate = template.Must(template.New("wiring").Parse(`// Code generated by wiringgen. DO NOT EDIT.
+
+package {{ .Name }}
+
+import (
+{{- range .StdImports }}
+	{{ . }}
+{{- end }}
+{{ range .Imports }}
+	{{ . }}
+{{- end }}
+)
+
+// {{ .TypeName }} resolves the providers of this package without reflection
+type {{ .TypeName }} struct {
+{{- range .Providers }}{{ if .Singleton }}
+	{{ .Field }}Once  sync.Once
+	{{ .Field }}Value {{ .Type }}
+	{{ .Field }}Err   error
+{{- end }}{{ end }}
+}
+
+// New{{ .TypeName }} returns a new {{ .TypeName }}. Singletons are shared by every call
+// made on the same instance.
+func New{{ .TypeName }}() *{{ .TypeName }} {
+	return &{{ .TypeName }}{}
+}
+{{ range .Providers }}
+// {{ .Method }} returns {{ if .Singleton }}the{{ else }}a new{{ end }} {{ .Type }} built with {{ .Func }}
+func (w *{{ $.TypeName }}) {{ .Method }}() ({{ .Type }}, error) {
+{{- if .Singleton }}
+	w.{{ .Field }}Once.Do(func() {
+		w.{{ .Field }}Value, w.{{ .Field }}Err = w.build{{ .Method }}()
+	})
+
+	return w.{{ .Field }}Value, w.{{ .Field }}Err
+}
+
+func (w *{{ $.TypeName }}) build{{ .Method }}() ({{ .Type }}, error) {
+{{- end }}
+{{- if .Args }}
+	var value {{ .Type }}
+{{- end }}
+{{- range $i, $arg := .Args }}
+	arg{{ $i }}, err := w.{{ $arg }}()
+	if err != nil {
+		return value, err
+	}
+{{- end }}
+	return {{ .Func }}({{ range $i, $arg := .Args }}{{ if $i }}, {{ end }}arg{{ $i }}{{ end }}){{ if not .ReturnsError }}, nil{{ end }}
+}
+{{ end }}
+// Register{{ .TypeName }} registers the same providers on a dynamic container, it's
+// meant to be used on tests that need to override some dependency.
+func Register{{ .TypeName }}(c *container.Container) error {
+	err := c.Transient(
+{{- range .Providers }}{{ if not .Singleton }}
+		{{ .Func }},
+{{- end }}{{ end }}
+	)
+	if err != nil {
+		return err
+	}
+
+	return c.Singleton(
+{{- range .Providers }}{{ if .Singleton }}
+		{{ .Func }},
+{{- end }}{{ end }}
+	)
+}
+`))
+
+// Generate returns the source of a file that resolves the providers of pkg
+// without reflection. The package is validated first so missing providers
+// and circular dependencies are reported while generating the code.
+func Generate(pkg *Package, options Options) ([]byte, error) {
+	err := pkg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if options.TypeName == "" {
+		options.TypeName = "Wiring"
+	}
+
+	methods := map[string]string{}
+	generated := generatedPackage{
+		Name:     pkg.Name,
+		TypeName: options.TypeName,
+	}
+	reserved := map[string]string{
+		"New" + options.TypeName:      "",
+		"Register" + options.TypeName: "",
+	}
+	for _, provider := range pkg.Providers {
+		method := methodName(provider.Func)
+		_, exists := reserved[method]
+		if exists {
+			return nil, errors.Errorf(errors.E_REDECLARED_DEPENDENCY, "accessor %s for %s is already used", method, provider.Func)
+		}
+
+		reserved[method] = provider.Func
+		methods[provider.Type] = method
+	}
+
+	hasSingletons := false
+	for _, provider := range pkg.Providers {
+		args := []string{}
+		for _, param := range provider.Params {
+			args = append(args, methods[param])
+		}
+
+		method := methods[provider.Type]
+		generated.Providers = append(generated.Providers, generatedProvider{
+			Provider: provider,
+			Method:   method,
+			Field:    string(unicode.ToLower(rune(method[0]))) + method[1:],
+			Args:     args,
+		})
+		hasSingletons = hasSingletons || provider.Singleton
+	}
+
+	imports := map[string]string{
+		"container": containerImport,
+	}
+	if hasSingletons {
+		imports["sync"] = "sync"
+	}
+	maps.Copy(imports, pkg.imports)
+	for _, name := range slices.Sorted(maps.Keys(imports)) {
+		importPath := imports[name]
+		spec := `"` + importPath + `"`
+		if !strings.HasSuffix(importPath, "/"+name) && importPath != name {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			generated.Imports = append(generated.Imports, spec)
+		} else {
+			generated.StdImports = append(generated.StdImports, spec)
+		}
+	}
+
+	var source bytes.Buffer
+	err = generatedTemplate.Execute(&source, generated)
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(source.Bytes())
+}
+
+// methodName returns the accessor name for a constructor. The New prefix is
+// removed and the name is exported.
+func methodName(constructor string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(constructor, "New"), "new")
+	if name == "" {
+		name = constructor
+	}
+
+	return string(unicode.ToUpper(rune(name[0]))) + name[1:]
+}
@@ -0,0 +1,275 @@
+package wiringgen
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+)
+
+// Directive marks a constructor as a provider. It must be on its own line
+// on the doc comment of a top level function and can be followed by the
+// singleton option.
+//
+//	//wiring:provide singleton
+//	func NewLogger() *slog.Logger
+const Directive = "//wiring:provide"
+
+// Provider is a constructor annotated with [Directive]
+type Provider struct {
+	// Func is the name of the constructor
+	Func string
+	// Type is the source expression of the provided type
+	Type string
+	// Params are the source expressions of the constructor parameters
+	Params       []string
+	ReturnsError bool
+	Singleton    bool
+}
+
+// Package holds the providers found on a package
+type Package struct {
+	Name      string
+	Providers []*Provider
+	// imports maps the package names used by providers to their paths
+	imports map[string]string
+}
+
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// Parse reads the non test go files of dir and returns the providers declared
+// on them. Files listed on exclude are ignored, it's used to skip previously
+// generated files.
+func Parse(dir string, exclude ...string) (*Package, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fileNames, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(fileNames)
+
+	pkg := &Package{
+		imports: map[string]string{},
+	}
+	fset := token.NewFileSet()
+	for _, fileName := range fileNames {
+		if strings.HasSuffix(fileName, "_test.go") || isExcluded(fileName, exclude) {
+			continue
+		}
+
+		source, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := parser.ParseFile(fset, fileName, source, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		if pkg.Name == "" {
+			pkg.Name = file.Name.Name
+		}
+
+		err = pkg.addFile(fset, file, dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if pkg.Name == "" {
+		return nil, errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "no go files found on %s", dir)
+	}
+
+	return pkg, nil
+}
+
+func isExcluded(fileName string, exclude []string) bool {
+	for _, excluded := range exclude {
+		if filepath.Base(fileName) == filepath.Base(excluded) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Package) addFile(fset *token.FileSet, file *ast.File, dir string) error {
+	fileImports := map[string]string{}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return err
+		}
+
+		fileImports[importName(spec, importPath, dir)] = importPath
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil {
+			continue
+		}
+
+		options, annotated := directiveOptions(fn.Doc)
+		if !annotated {
+			continue
+		}
+
+		provider, err := newProvider(fset, fn, options)
+		if err != nil {
+			return err
+		}
+
+		for _, expr := range providerExprs(fn) {
+			for _, name := range referencedPackages(expr) {
+				importPath, ok := fileImports[name]
+				if !ok {
+					return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "%s: provider %s references package %s which doesn't match any import", fset.Position(fn.Pos()), fn.Name.Name, name)
+				}
+				p.imports[name] = importPath
+			}
+		}
+
+		p.Providers = append(p.Providers, provider)
+	}
+
+	return nil
+}
+
+// importName returns the name used to reference an import from dir. When
+// the import has no explicit name the name of the imported package is used.
+// If the package cannot be loaded the last element of the path is used
+// skipping version suffixes like v2.
+func importName(spec *ast.ImportSpec, importPath string, dir string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+
+	imported, err := build.Import(importPath, dir, 0)
+	if err == nil && imported.Name != "" {
+		return imported.Name
+	}
+
+	name := path.Base(importPath)
+	if versionSuffix.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+
+	return name
+}
+
+func directiveOptions(doc *ast.CommentGroup) ([]string, bool) {
+	if doc == nil {
+		return nil, false
+	}
+
+	for _, comment := range doc.List {
+		fields := strings.Fields(comment.Text)
+		if len(fields) > 0 && fields[0] == Directive {
+			return fields[1:], true
+		}
+	}
+
+	return nil, false
+}
+
+func newProvider(fset *token.FileSet, fn *ast.FuncDecl, options []string) (*Provider, error) {
+	position := fset.Position(fn.Pos())
+	if fn.Type.TypeParams != nil {
+		return nil, errors.Errorf(errors.E_INVALID_RESOLVER, "%s: provider %s cannot be generic", position, fn.Name.Name)
+	}
+
+	provider := &Provider{
+		Func: fn.Name.Name,
+	}
+
+	for _, option := range options {
+		switch option {
+		case "singleton":
+			provider.Singleton = true
+		default:
+			return nil, errors.Errorf(errors.E_INVALID_RESOLVER, "%s: unknown option '%s' for provider %s", position, option, fn.Name.Name)
+		}
+	}
+
+	for _, param := range fn.Type.Params.List {
+		if _, variadic := param.Type.(*ast.Ellipsis); variadic {
+			return nil, errors.Errorf(errors.E_INVALID_RESOLVER, "%s: provider %s cannot be variadic", position, fn.Name.Name)
+		}
+
+		count := max(len(param.Names), 1)
+		for range count {
+			provider.Params = append(provider.Params, types.ExprString(param.Type))
+		}
+	}
+
+	results := fn.Type.Results
+	if results == nil || results.NumFields() == 0 || results.NumFields() > 2 {
+		return nil, errors.Errorf(errors.E_INVALID_RESOLVER, "%s: provider %s should return a value and optionally an error", position, fn.Name.Name)
+	}
+
+	resultTypes := []string{}
+	for _, result := range results.List {
+		count := max(len(result.Names), 1)
+		for range count {
+			resultTypes = append(resultTypes, types.ExprString(result.Type))
+		}
+	}
+
+	if len(resultTypes) == 2 {
+		if resultTypes[1] != "error" {
+			return nil, errors.Errorf(errors.E_INVALID_RESOLVER, "%s: provider %s should return an error as second value", position, fn.Name.Name)
+		}
+		provider.ReturnsError = true
+	}
+	provider.Type = resultTypes[0]
+
+	return provider, nil
+}
+
+// providerExprs returns the type expressions used on the signature of fn
+func providerExprs(fn *ast.FuncDecl) []ast.Expr {
+	exprs := []ast.Expr{}
+	for _, param := range fn.Type.Params.List {
+		exprs = append(exprs, param.Type)
+	}
+	for _, result := range fn.Type.Results.List {
+		exprs = append(exprs, result.Type)
+	}
+
+	return exprs
+}
+
+// referencedPackages returns the package names referenced by expr
+func referencedPackages(expr ast.Expr) []string {
+	names := []string{}
+	ast.Inspect(expr, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := selector.X.(*ast.Ident)
+		if ok {
+			names = append(names, ident.Name)
+		}
+		return false
+	})
+
+	return names
+}
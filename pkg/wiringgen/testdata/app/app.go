@@ -0,0 +1,43 @@
+package app
+
+import (
+	"io"
+	"log/slog"
+	"os"
+)
+
+type Repository struct {
+	logger *slog.Logger
+}
+
+type Service struct {
+	repository *Repository
+	logger     *slog.Logger
+}
+
+//wiring:provide
+func NewWriter() io.Writer {
+	return os.Stdout
+}
+
+//wiring:provide singleton
+func NewLogger(writer io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(writer, nil))
+}
+
+// NewRepository is resolved on every call
+//
+//wiring:provide
+func NewRepository(logger *slog.Logger) (*Repository, error) {
+	return &Repository{logger: logger}, nil
+}
+
+//wiring:provide singleton
+func NewService(repository *Repository, logger *slog.Logger) *Service {
+	return &Service{repository: repository, logger: logger}
+}
+
+// not a provider
+func NewUnused() int {
+	return 0
+}
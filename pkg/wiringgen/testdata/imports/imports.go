@@ -0,0 +1,10 @@
+package imports
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+//wiring:provide
+func NewNode() *yaml.Node {
+	return &yaml.Node{}
+}
@@ -0,0 +1,76 @@
+package wiringgen
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/internal/collections/graph"
+)
+
+// Validate builds the dependency graph of the providers. It reports types
+// with more than one provider, parameters without provider and circular
+// dependencies, the same failures a container reports when resolving.
+func (p *Package) Validate() error {
+	g := graph.NewGraph[*Provider]()
+	nodes := map[string]*graph.Node[*Provider]{}
+	for _, provider := range p.Providers {
+		existing, exists := nodes[provider.Type]
+		if exists {
+			return errors.Errorf(
+				errors.E_REDECLARED_DEPENDENCY,
+				"%s is provided by %s and %s",
+				provider.Type,
+				existing.Val.Func,
+				provider.Func)
+		}
+
+		node := graph.NewNode(provider)
+		g.Add(node)
+		nodes[provider.Type] = node
+	}
+
+	failures := []error{}
+	for _, provider := range p.Providers {
+		node := nodes[provider.Type]
+		for _, param := range provider.Params {
+			if param == provider.Type {
+				return errors.Errorf(errors.E_CIRCULAR_DEPENDENCY, "circular dependency found: %s depends on itself", provider.Func)
+			}
+
+			dependencyNode, ok := nodes[param]
+			if !ok {
+				failures = append(failures, errors.Errorf(
+					errors.E_DEPENDENCY_NOT_FOUND,
+					"%s requires %s which has no provider",
+					provider.Func,
+					param))
+				continue
+			}
+
+			// a single connection is kept between two nodes so a
+			// dependency in the opposite direction is a cycle
+			if slices.Contains(node.GetOutgoingNodes(), dependencyNode) {
+				return errors.Errorf(errors.E_CIRCULAR_DEPENDENCY, "circular dependency found: %s -> %s -> %s", provider.Func, dependencyNode.Val.Func, provider.Func)
+			}
+
+			g.Connect(dependencyNode, node, graph.OUT)
+		}
+	}
+
+	err := errors.Join(failures...)
+	if err != nil {
+		return err
+	}
+
+	cycle, found := g.DetectCircularRelations()
+	if found {
+		names := make([]string, 0, len(cycle))
+		for _, node := range cycle {
+			names = append(names, node.Val.Func)
+		}
+		return errors.Errorf(errors.E_CIRCULAR_DEPENDENCY, "circular dependency found: %s", strings.Join(names, " -> "))
+	}
+
+	return nil
+}
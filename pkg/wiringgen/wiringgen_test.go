@@ -0,0 +1,116 @@
+package wiringgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	pkg, err := Parse("testdata/app")
+	require.NoError(t, err)
+	require.Len(t, pkg.Providers, 4, "only annotated constructors should be providers")
+
+	source, err := Generate(pkg, Options{})
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("testdata/app/wiring_gen.go.golden")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(source))
+}
+
+func parseSource(t *testing.T, source string) *Package {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(source), 0o644)
+	require.NoError(t, err)
+
+	pkg, err := Parse(dir)
+	require.NoError(t, err)
+	return pkg
+}
+
+func requireCode(t *testing.T, err error, code any) {
+	var wiringErr *errors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, code, wiringErr.Code())
+}
+
+func TestValidate_MissingProvider(t *testing.T) {
+	pkg := parseSource(t, `package app
+
+//wiring:provide
+func NewService(name string) *int { return nil }
+`)
+
+	_, err := Generate(pkg, Options{})
+	requireCode(t, err, errors.E_DEPENDENCY_NOT_FOUND)
+}
+
+func TestValidate_Cycle(t *testing.T) {
+	pkg := parseSource(t, `package app
+
+type A struct{}
+type B struct{}
+
+//wiring:provide
+func NewA(b *B) *A { return nil }
+
+//wiring:provide
+func NewB(a *A) *B { return nil }
+`)
+
+	err := pkg.Validate()
+	requireCode(t, err, errors.E_CIRCULAR_DEPENDENCY)
+}
+
+func TestValidate_Redeclared(t *testing.T) {
+	pkg := parseSource(t, `package app
+
+//wiring:provide
+func NewA() int { return 0 }
+
+//wiring:provide
+func NewB() int { return 0 }
+`)
+
+	err := pkg.Validate()
+	requireCode(t, err, errors.E_REDECLARED_DEPENDENCY)
+}
+
+func TestParse_InvalidProvider(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(`package app
+
+//wiring:provide
+func NewA() (int, string) { return 0, "" }
+`), 0o644)
+	require.NoError(t, err)
+
+	_, err = Parse(dir)
+	requireCode(t, err, errors.E_INVALID_RESOLVER)
+}
+
+func TestParse_ImportName(t *testing.T) {
+	pkg, err := Parse("testdata/imports")
+	require.NoError(t, err)
+
+	source, err := Generate(pkg, Options{})
+	require.NoError(t, err)
+	require.Contains(t, string(source), `yaml "gopkg.in/yaml.v3"`, "the name of the package should be used instead of the path")
+}
+
+func TestParse_UnknownPackage(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(`package app
+
+//wiring:provide
+func NewNode() *yaml.Node { return nil }
+`), 0o644)
+	require.NoError(t, err)
+
+	_, err = Parse(dir)
+	requireCode(t, err, errors.E_DEPENDENCY_NOT_FOUND)
+}
@@ -0,0 +1,184 @@
+package wiringhttp
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+)
+
+// parameterKind sets how a parameter of a handler function is filled
+type parameterKind int
+
+const (
+	injectedParameter parameterKind = iota
+	writerParameter
+	requestParameter
+	contextParameter
+)
+
+var (
+	writerType  = reflect.TypeFor[http.ResponseWriter]()
+	requestType = reflect.TypeFor[*http.Request]()
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
+)
+
+// handlerPlan holds how to call a handler function. It's computed once when
+// the handler is built so serving a request only resolves the parameters.
+type handlerPlan struct {
+	function     reflect.Value
+	kinds        []parameterKind
+	types        []reflect.Type
+	returnsError bool
+}
+
+// Handler returns an [http.HandlerFunc] that calls fn. The response
+// writer, the request and its context are passed as is and the rest of
+// parameters are resolved from the scope of the request, so requests must
+// go through [Middleware].
+//
+//	handler, err := wiringhttp.Handler(root, func(w http.ResponseWriter, r *http.Request, users *UserService) error {
+//		// ...
+//	}, RequestModule)
+//
+// Parameters are validated against a scope of root with modules installed,
+// the ones given to [Install], so a parameter without resolver is reported
+// when the handler is built. Errors resolving the parameters are reported
+// to [OnError] and the handler responds with an internal server error. fn
+// may return an error, which is only reported since fn may have written
+// the response already.
+func Handler(root *container.Container, fn any, modules ...*container.Module) (http.HandlerFunc, error) {
+	plan, err := compileHandler(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	err = plan.validate(root, modules)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.serve, nil
+}
+
+// MustHandler works like [Handler] but panics when fn is not a valid
+// handler function or its parameters cannot be resolved
+func MustHandler(root *container.Container, fn any, modules ...*container.Module) http.HandlerFunc {
+	handler, err := Handler(root, fn, modules...)
+	if err != nil {
+		panic(err)
+	}
+
+	return handler
+}
+
+func compileHandler(fn any) (*handlerPlan, error) {
+	if fn == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		return nil, errors.Errorf(errors.E_INVALID_RESOLVER, "handler should be a function, %T was given", fn)
+	}
+
+	function := reflect.ValueOf(fn)
+	functionType := function.Type()
+	returnsError := functionType.NumOut() == 1 && functionType.Out(0) == errorType
+	if functionType.NumOut() > 0 && !returnsError {
+		return nil, errors.Errorf(errors.E_INVALID_RESOLVER, "handler %v should return nothing or an error", functionType)
+	}
+
+	plan := &handlerPlan{
+		function:     function,
+		kinds:        make([]parameterKind, functionType.NumIn()),
+		types:        make([]reflect.Type, functionType.NumIn()),
+		returnsError: returnsError,
+	}
+	for i := range plan.kinds {
+		inputType := functionType.In(i)
+		plan.types[i] = inputType
+
+		switch inputType {
+		case writerType:
+			plan.kinds[i] = writerParameter
+		case requestType:
+			plan.kinds[i] = requestParameter
+		case contextType:
+			plan.kinds[i] = contextParameter
+		default:
+			plan.kinds[i] = injectedParameter
+		}
+	}
+
+	return plan, nil
+}
+
+// handlerProbe is the type returned by the resolver registered to validate
+// the parameters of a handler
+type handlerProbe struct{}
+
+// validate registers a resolver with the injected parameters of the handler
+// on a scope of root, so they are validated like the ones of any other
+// resolver
+func (p *handlerPlan) validate(root *container.Container, modules []*container.Module) error {
+	scope, err := probe(root, modules)
+	if err != nil {
+		return err
+	}
+
+	inputTypes := []reflect.Type{}
+	for i, kind := range p.kinds {
+		if kind == injectedParameter {
+			inputTypes = append(inputTypes, p.types[i])
+		}
+	}
+
+	probeType := reflect.TypeFor[handlerProbe]()
+	resolver := reflect.MakeFunc(reflect.FuncOf(inputTypes, []reflect.Type{probeType}, false), func(args []reflect.Value) []reflect.Value {
+		return []reflect.Value{reflect.Zero(probeType)}
+	})
+	err = scope.Transient(resolver.Interface())
+	if err != nil {
+		return err
+	}
+
+	_, err = scope.Graph()
+	return err
+}
+
+func (p *handlerPlan) serve(w http.ResponseWriter, r *http.Request) {
+	args := make([]reflect.Value, len(p.kinds))
+	for i, kind := range p.kinds {
+		switch kind {
+		case writerParameter:
+			args[i] = reflect.ValueOf(&w).Elem()
+		case requestParameter:
+			args[i] = reflect.ValueOf(r)
+		case contextParameter:
+			ctx := r.Context()
+			args[i] = reflect.ValueOf(&ctx).Elem()
+		default:
+			scope, ok := Scope(r)
+			if !ok {
+				internalError(w)
+				return
+			}
+
+			value, err := scope.ResolveType(p.types[i])
+			if err != nil {
+				reportError(r, err)
+				internalError(w)
+				return
+			}
+			args[i] = value
+		}
+	}
+
+	out := p.function.Call(args)
+	if p.returnsError && !out[0].IsNil() {
+		reportError(r, out[0].Interface().(error))
+	}
+}
+
+func internalError(w http.ResponseWriter) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
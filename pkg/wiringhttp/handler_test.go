@@ -0,0 +1,102 @@
+package wiringhttp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/wiringhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type missing struct{}
+
+// failing is a dependency whose resolver fails on every request
+type failing struct{}
+
+func TestHandler(t *testing.T) {
+	root := newRoot()
+	handler, err := wiringhttp.Handler(root, func(w http.ResponseWriter, r *http.Request, ctx context.Context, g *greeter, s *session) {
+		assert.Equal(t, r.Context(), ctx)
+		fmt.Fprintf(w, "%s %s", g.greeting, s.user)
+	}, sessions)
+	require.NoError(t, err)
+
+	middleware := wiringhttp.MustMiddleware(root, wiringhttp.Install(sessions))
+	recorder := httptest.NewRecorder()
+	middleware(handler).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?user=alice", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "hello alice", recorder.Body.String())
+}
+
+func TestHandler_Errors(t *testing.T) {
+	root := newRoot()
+	failure := errors.New("failure")
+	root.Transient(func() (*failing, error) {
+		return nil, failure
+	})
+
+	var reported []error
+	middleware := wiringhttp.MustMiddleware(root, wiringhttp.OnError(func(r *http.Request, err error) {
+		reported = append(reported, err)
+	}))
+
+	recorder := httptest.NewRecorder()
+	middleware(wiringhttp.MustHandler(root, func(f *failing) {})).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	require.Equal(t, []error{failure}, reported)
+
+	recorder = httptest.NewRecorder()
+	middleware(wiringhttp.MustHandler(root, func(w http.ResponseWriter) error {
+		w.WriteHeader(http.StatusConflict)
+		return failure
+	})).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	require.Equal(t, []error{failure, failure}, reported)
+
+	recorder = httptest.NewRecorder()
+	wiringhttp.MustHandler(root, func(g *greeter) {}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusInternalServerError, recorder.Code, "requests without scope should fail")
+}
+
+func TestHandler_Invalid(t *testing.T) {
+	root := newRoot()
+	for _, fn := range []any{nil, "handler", func() int { return 0 }} {
+		_, err := wiringhttp.Handler(root, fn)
+		var wiringErr *wiringerrors.WiringError
+		require.ErrorAs(t, err, &wiringErr)
+		require.Equal(t, wiringerrors.E_INVALID_RESOLVER, wiringErr.Code())
+	}
+
+	_, err := wiringhttp.Handler(root, func(w http.ResponseWriter, m *missing) {})
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+
+	_, err = wiringhttp.Handler(root, func(s *session) {})
+	require.Error(t, err, "modules installed on the scopes should be given to the handler")
+	_, err = wiringhttp.Handler(root, func(s *session) {}, sessions)
+	require.NoError(t, err)
+}
+
+func BenchmarkHandler(b *testing.B) {
+	root := container.New()
+	root.Singleton(func() *greeter {
+		return &greeter{greeting: "hello"}
+	})
+	handler := wiringhttp.MustHandler(root, func(w http.ResponseWriter, g *greeter) {})
+	served := wiringhttp.MustMiddleware(root)(handler)
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	b.ResetTimer()
+	for range b.N {
+		served.ServeHTTP(httptest.NewRecorder(), request)
+	}
+}
@@ -0,0 +1,204 @@
+// Package wiringhttp integrates containers with net/http. Every request
+// gets its own scope, a container derived from the root one where the
+// request, its response writer and its context are registered.
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+//		users, err := wiringhttp.Resolve[*UserService](r)
+//		// ...
+//	})
+//
+//	http.ListenAndServe(":8080", wiringhttp.MustMiddleware(root)(mux))
+package wiringhttp
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	"github.com/4strodev/wiring_graphs/pkg/errors"
+)
+
+// scopeKey is the key of the request scope in the request context
+type scopeKey struct{}
+
+// requestScope is the value stored in the request context
+type requestScope struct {
+	container  *container.Container
+	middleware *middleware
+}
+
+// Option customizes the request scopes created by [Middleware]
+type Option func(m *middleware)
+
+// Install installs modules on every request scope. It's used to register
+// resolvers that depend on the request, which can't be registered on the
+// root container.
+func Install(modules ...*container.Module) Option {
+	return func(m *middleware) {
+		m.modules = append(m.modules, modules...)
+	}
+}
+
+// OnError sets the function called when creating or closing a request
+// scope fails, or a handler built with [Handler] fails. Errors are ignored
+// by default. When the scope cannot be created the middleware responds with
+// an internal server error.
+func OnError(fn func(r *http.Request, err error)) Option {
+	return func(m *middleware) {
+		m.onError = fn
+	}
+}
+
+type middleware struct {
+	root    *container.Container
+	modules []*container.Module
+	onError func(r *http.Request, err error)
+}
+
+// Middleware returns a middleware that creates a scope derived from root
+// for each request. The scope is closed when the request finishes, so
+// singletons registered on it live as long as the request. root is
+// connected and validated together with the installed modules when the
+// middleware is created, so wiring errors are reported on startup instead
+// of on every request.
+func Middleware(root *container.Container, options ...Option) (func(http.Handler) http.Handler, error) {
+	m := &middleware{
+		root: root,
+	}
+	for _, option := range options {
+		option(m)
+	}
+
+	_, err := probe(root, m.modules)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := root.Derived()
+			r = r.WithContext(context.WithValue(r.Context(), scopeKey{}, &requestScope{
+				container:  scope,
+				middleware: m,
+			}))
+
+			err := m.register(scope, w, r)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				m.reportError(r, err)
+				return
+			}
+
+			defer func() {
+				err := scope.Close()
+				if err != nil {
+					m.reportError(r, err)
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// MustMiddleware works like [Middleware] but panics when root cannot be
+// connected
+func MustMiddleware(root *container.Container, options ...Option) func(http.Handler) http.Handler {
+	middleware, err := Middleware(root, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return middleware
+}
+
+// register adds the request dependencies and the modules to scope
+func (m *middleware) register(scope *container.Container, w http.ResponseWriter, r *http.Request) error {
+	return register(scope, m.modules, w, r)
+}
+
+// probe returns a connected scope of root like the ones created for the
+// requests. Nothing is resolved from it, it's used to validate the graph.
+func probe(root *container.Container, modules []*container.Module) (*container.Container, error) {
+	scope := root.Derived()
+	err := register(scope, modules, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = scope.Graph()
+	if err != nil {
+		return nil, err
+	}
+
+	return scope, nil
+}
+
+// register adds the request dependencies of w and r and the modules to
+// scope
+func register(scope *container.Container, modules []*container.Module, w http.ResponseWriter, r *http.Request) error {
+	err := scope.Singleton(func() *http.Request {
+		return r
+	}, func() http.ResponseWriter {
+		return w
+	}, func() context.Context {
+		return r.Context()
+	})
+	if err != nil {
+		return err
+	}
+
+	return scope.Install(modules...)
+}
+
+func (m *middleware) reportError(r *http.Request, err error) {
+	if m.onError != nil {
+		m.onError(r, err)
+	}
+}
+
+// Scope returns the container of the request created by [Middleware]
+func Scope(r *http.Request) (*container.Container, bool) {
+	scope, ok := r.Context().Value(scopeKey{}).(*requestScope)
+	if !ok {
+		return nil, false
+	}
+
+	return scope.container, true
+}
+
+// reportError reports err to the middleware that created the scope of r
+func reportError(r *http.Request, err error) {
+	scope, ok := r.Context().Value(scopeKey{}).(*requestScope)
+	if ok {
+		scope.middleware.reportError(r, err)
+	}
+}
+
+// Resolve resolves T from the scope of the request
+func Resolve[T any](r *http.Request) (T, error) {
+	scope, ok := Scope(r)
+	if !ok {
+		var dependency T
+		return dependency, errNoScope(reflect.TypeFor[T]())
+	}
+
+	return container.Resolve[T](scope)
+}
+
+// ResolveToken resolves token from the scope of the request
+func ResolveToken[T any](r *http.Request, token string) (T, error) {
+	scope, ok := Scope(r)
+	if !ok {
+		var dependency T
+		return dependency, errNoScope(reflect.TypeFor[T]())
+	}
+
+	return container.ResolveToken[T](scope, token)
+}
+
+func errNoScope(t reflect.Type) error {
+	return errors.Errorf(errors.E_DEPENDENCY_NOT_FOUND, "cannot resolve %v, the request has no scope, use wiringhttp.Middleware", t)
+}
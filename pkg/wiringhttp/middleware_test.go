@@ -0,0 +1,185 @@
+package wiringhttp_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/4strodev/wiring_graphs/pkg/container"
+	wiringerrors "github.com/4strodev/wiring_graphs/pkg/errors"
+	"github.com/4strodev/wiring_graphs/pkg/wiringhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greeter struct {
+	greeting string
+}
+
+// session is built for each request and closed when it finishes
+type session struct {
+	user   string
+	closed bool
+}
+
+func (s *session) Close() error {
+	s.closed = true
+	return nil
+}
+
+func newRoot() *container.Container {
+	root := container.New()
+	root.Singleton(func() *greeter {
+		return &greeter{greeting: "hello"}
+	})
+
+	return root
+}
+
+var sessions = container.NewModule("session").Singleton(func(r *http.Request) *session {
+	return &session{user: r.URL.Query().Get("user")}
+})
+
+func TestMiddleware(t *testing.T) {
+	var requestSession *session
+	// handlers run on the server goroutines so they use assert, failures
+	// are detected on the test goroutine through the response
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g, err := wiringhttp.Resolve[*greeter](r)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		requestSession, err = wiringhttp.Resolve[*session](r)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		again, err := wiringhttp.Resolve[*session](r)
+		assert.NoError(t, err)
+		assert.Same(t, requestSession, again)
+
+		ctx, err := wiringhttp.Resolve[context.Context](r)
+		assert.NoError(t, err)
+		assert.Equal(t, r.Context(), ctx)
+
+		writer, err := wiringhttp.Resolve[http.ResponseWriter](r)
+		if !assert.NoError(t, err) {
+			return
+		}
+		fmt.Fprintf(writer, "%s %s", g.greeting, requestSession.user)
+	})
+
+	server := httptest.NewServer(wiringhttp.MustMiddleware(newRoot(), wiringhttp.Install(sessions))(handler))
+	defer server.Close()
+
+	for _, user := range []string{"alice", "bob"} {
+		response, err := http.Get(server.URL + "?user=" + user)
+		require.NoError(t, err)
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		require.NoError(t, err)
+
+		require.Equal(t, "hello "+user, string(body))
+		require.NotNil(t, requestSession)
+		require.Equal(t, user, requestSession.user, "each request should have its own scope")
+		require.True(t, requestSession.closed, "scope should be closed when the request finishes")
+	}
+}
+
+func TestMiddleware_ConcurrentRequests(t *testing.T) {
+	var built atomic.Int32
+	root := container.New()
+	root.Singleton(container.Conditional(func() *greeter {
+		built.Add(1)
+		return &greeter{greeting: "hello"}
+	}, container.When(func(c *container.Container) bool {
+		return true
+	})))
+
+	const requests = 20
+	// every request waits for the rest so their scopes are resolved
+	// at the same time
+	var arrived sync.WaitGroup
+	arrived.Add(requests)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived.Done()
+		arrived.Wait()
+
+		g, err := wiringhttp.Resolve[*greeter](r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s, err := wiringhttp.Resolve[*session](r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprintf(w, "%s %s", g.greeting, s.user)
+	})
+
+	server := httptest.NewServer(wiringhttp.MustMiddleware(root, wiringhttp.Install(sessions))(handler))
+	defer server.Close()
+
+	bodies := make([]string, requests)
+	failures := make([]error, requests)
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := http.Get(fmt.Sprintf("%s?user=%d", server.URL, i))
+			if err != nil {
+				failures[i] = err
+				return
+			}
+			defer response.Body.Close()
+
+			body, err := io.ReadAll(response.Body)
+			bodies[i], failures[i] = string(body), err
+		}()
+	}
+	wg.Wait()
+
+	for i := range requests {
+		require.NoError(t, failures[i])
+		require.Equal(t, fmt.Sprintf("hello %d", i), bodies[i])
+	}
+	require.Equal(t, int32(1), built.Load(), "singletons of the root should be built once")
+}
+
+func TestMiddleware_ValidatesRoot(t *testing.T) {
+	root := newRoot()
+	conflicting := container.NewModule("session").Transient(func() *greeter {
+		return &greeter{}
+	})
+
+	_, err := wiringhttp.Middleware(root, wiringhttp.Install(sessions, conflicting))
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DUPLICATE_MODULE, wiringErr.Code())
+
+	root.Singleton(func(m *missing) *session {
+		return &session{}
+	})
+	_, err = wiringhttp.Middleware(root)
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+	require.Panics(t, func() {
+		wiringhttp.MustMiddleware(root)
+	})
+}
+
+func TestResolve_WithoutScope(t *testing.T) {
+	_, err := wiringhttp.Resolve[*greeter](httptest.NewRequest(http.MethodGet, "/", nil))
+	var wiringErr *wiringerrors.WiringError
+	require.ErrorAs(t, err, &wiringErr)
+	require.Equal(t, wiringerrors.E_DEPENDENCY_NOT_FOUND, wiringErr.Code())
+}